@@ -1,175 +1,1042 @@
 // tinystat is used to compare two or more sets of measurements (e.g., runs of a multiple runs of
 // benchmarks of two possible implementations) and determine if they are statistically different.
 // It's inspired largely by FreeBSD's ministat (written by Poul-Henning Kamp).
+//
+// tinystat exits with a status of 0 on success. If the --fail-on-regression flag is given and any
+// experiment's mean is significantly greater than the control's, it exits with a status of 1. If
+// the command line arguments are invalid, it exits with a status of 2, and if any other error
+// occurs, it exits with a status of 255.
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"image/color"
+	"io"
+	"math"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 
+	svg "github.com/ajstarks/svgo"
 	"github.com/alecthomas/kong"
 	"github.com/codahale/tinystat"
 	"github.com/vdobler/chart"
+	"github.com/vdobler/chart/svgg"
 	"github.com/vdobler/chart/txtg"
 )
 
 var version = "dev"
 
+// The exit statuses of tinystat.
+const (
+	exitRegression = 1
+	exitUsage      = 2
+	exitError      = 255
+)
+
+// defaultConfidence is the confidence level used if neither --confidence nor --alpha is given.
+const defaultConfidence = 95
+
+// formatMarkdown is the table format which renders a GitHub-flavored Markdown table.
+const formatMarkdown = "markdown"
+
+// The dimensions, in pixels, and font size of charts rendered as SVG.
+const (
+	svgWidth    = 800
+	svgHeight   = 600
+	svgFontSize = 12
+)
+
+// The orders in which experiments can be sorted.
+const (
+	sortP      = "p"
+	sortEffect = "effect"
+	sortMean   = "mean"
+)
+
+// delimiterAuto is the delimiter which detects the actual delimiter from the input.
+const delimiterAuto = "auto"
+
+// The maximum number of bytes and lines to examine when detecting the delimiter.
+const (
+	sniffSize  = 4096
+	sniffLines = 5
+)
+
+// gzipMagic is the header which begins all gzip-compressed files.
+//
+//nolint:gochecknoglobals // can't have const byte slices
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// chartHistogram is the chart type which renders a histogram of each data set instead of a box
+// chart.
+const chartHistogram = "histogram"
+
+// stdin is the filename which refers to standard input rather than a file.
+const stdin = "-"
+
+// config is the command line configuration.
+//
+//nolint:maligned // ordering of fields matters
+type config struct {
+	Confidence       float64          `short:"C" help:"Confidence level for statistical significance (0,100). Defaults to 95."` //nolint:lll // can't format struct field tags
+	Alpha            float64          `help:"Significance level (0,1), as an alternative to --confidence."`
+	Column           int              `short:"c" default:"0" help:"The CSV column to analyze."`
+	Columns          []int            `help:"A comma-separated list of CSV columns to analyze separately."`
+	Delimiter        string           `short:"d" default:"auto" help:"The CSV delimiter to use, or auto to detect it."`
+	Header           bool             `default:"false" help:"Skip the first row of each CSV file, using it to label columns."`           //nolint:lll // can't format struct field tags
+	Format           string           `enum:"text,markdown" default:"text" help:"The format of the comparison table (text, markdown)."`  //nolint:lll // can't format struct field tags
+	CI               bool             `default:"false" help:"Also print the confidence interval of each difference in means."`           //nolint:lll // can't format struct field tags
+	TrimOutliers     bool             `default:"false" help:"Remove outliers outside the Tukey fences from each file before comparing."` //nolint:lll // can't format struct field tags
+	Unit             string           `help:"The unit of the measurements (e.g., ns), used to label the chart and table."`
+	LogScale         bool             `default:"false" help:"Use a logarithmic scale for the box chart's values. Can't be used with --chart histogram."` //nolint:lll // can't format struct field tags
+	Precision        int              `default:"2" help:"The number of digits to print after the decimal point."`
+	Scientific       bool             `default:"false" help:"Print means and standard deviations in scientific notation."`
+	Sort             string           `enum:"none,p,effect,mean" default:"none" help:"Sort experiments by p-value, effect, or mean (none, p, effect, mean)."` //nolint:lll // can't format struct field tags
+	Relative         bool             `default:"false" help:"Also show differences as percentages of the control's mean."`
+	Chart            string           `enum:"box,histogram" default:"box" help:"The type of chart to display (box, histogram)."` //nolint:lll // can't format struct field tags
+	NoChart          bool             `default:"false" help:"Don't display the box chart.'"`
+	SVG              string           `help:"Also write the box chart to the given SVG file."`
+	Out              string           `help:"Also write the comparison to the given CSV file."`
+	FailOnRegression bool             `default:"false" help:"Exit with a status of 1 if any experiment's mean is significantly greater than the control's."` //nolint:lll // can't format struct field tags
+	JSON             bool             `default:"false" help:"Output the comparison as JSON instead of a table."`
+	Width            int              `default:"74" help:"The width of the box chart in chars."`
+	Height           int              `default:"20" help:"The height of the box chart in chars."`
+	Version          kong.VersionFlag `help:"Display the application version."`
+	ControlPath      string           `arg:"" help:"The CSV file containing measurements of the control group, or - for stdin."`            //nolint:lll // can't format struct field tags
+	ExperimentPaths  []string         `arg:"" optional:"" help:"CSV files containing measurements of experimental groups, or - for stdin."` //nolint:lll // can't format struct field tags
+}
+
 func main() {
-	//nolint:maligned // ordering of fields matters
-	var cli struct {
-		//nolint:lll // can't format struct field tags
-		Confidence      float64          `short:"C" default:"95" help:"Confidence level for statistical significance (0,100)."`
-		Column          int              `short:"c" default:"0" help:"The CSV column to analyze."`
-		Delimiter       string           `short:"d" default:"," help:"The CSV delimiter to use."`
-		NoChart         bool             `default:"false" help:"Don't display the box chart.'"`
-		Width           int              `default:"74" help:"The width of the box chart in chars."`
-		Height          int              `default:"20" help:"The height of the box chart in chars."`
-		Version         kong.VersionFlag `help:"Display the application version."`
-		ControlPath     string           `arg:"" type:"existingfile" help:"The CSV file containing measurements of the control group."`            //nolint:lll // can't format struct field tags
-		ExperimentPaths []string         `arg:"" optional:"" type:"existingfile" help:"CSV files containing measurements of experimental groups."` //nolint:lll // can't format struct field tags
-	}
-
-	ctx := kong.Parse(&cli, kong.Vars{"version": version})
+	var cli config
+
+	ctx := kong.Parse(&cli, kong.Vars{"version": version}, kong.Exit(func(status int) {
+		if status != 0 {
+			status = exitUsage
+		}
+
+		os.Exit(status)
+	}))
 	if ctx.Error != nil {
 		_, _ = fmt.Fprintln(os.Stderr, ctx.Error)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
-	// read the data
-	controlData, experimentData, err := readData(cli.ControlPath, cli.ExperimentPaths, cli.Column, cli.Delimiter)
+	regression, err := run(&cli)
 	if err != nil {
+		var usage usageError
+		if errors.As(err, &usage) {
+			_, _ = fmt.Fprintf(os.Stderr, "tinystat: error: %s\n", usage)
+			os.Exit(exitUsage)
+		}
+
 		_, _ = fmt.Fprintln(os.Stderr, err)
-		os.Exit(-1)
+		os.Exit(exitError)
+	}
+
+	if regression && cli.FailOnRegression {
+		os.Exit(exitRegression)
+	}
+}
+
+// A usageError is an error in the command line arguments which kong can't detect by itself.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// run reads, compares, and outputs the data, returning true if any experiment is a regression.
+func run(cli *config) (bool, error) {
+	columns := cli.Columns
+	if len(columns) == 0 {
+		columns = []int{cli.Column}
+	}
+
+	level, err := confidence(cli.Confidence, cli.Alpha)
+	if err != nil {
+		return false, err
+	}
+
+	if err := checkPaths(cli.ControlPath, cli.ExperimentPaths); err != nil {
+		return false, err
+	}
+
+	if cli.LogScale && cli.Chart == chartHistogram {
+		return false, usageError("--log-scale can't be used with --chart histogram")
+	}
+
+	// read the data
+	experimentPaths, err := expandGlobs(cli.ExperimentPaths)
+	if err != nil {
+		return false, err
 	}
 
-	// chart the data
-	if !cli.NoChart {
-		printChart(cli.ExperimentPaths, cli.ControlPath, controlData, experimentData, cli.Width, cli.Height)
+	controlData, experimentData, names, err := readData(cli.ControlPath, experimentPaths, columns,
+		cli.Delimiter, cli.Header, cli.TrimOutliers)
+	if err != nil {
+		return false, err
 	}
 
 	// compare the data
-	if len(cli.ExperimentPaths) > 0 {
-		printComparison(cli.ControlPath, controlData, cli.ExperimentPaths, experimentData, cli.Confidence)
+	results, err := compareColumns(cli.ControlPath, controlData, experimentPaths, experimentData,
+		columns, level, cli.Sort)
+	if err != nil {
+		return false, err
+	}
+
+	// chart the data, only using a log scale if the box chart is drawn to the terminal or an SVG
+	drawn := cli.SVG != "" || !(cli.NoChart || cli.JSON || cli.Chart == chartHistogram)
+
+	charts, err := boxCharts(columns, results, cli.Unit, cli.LogScale && drawn)
+	if err != nil {
+		return false, err
+	}
+
+	// write the chart and comparison to files
+	if err := writeFiles(cli.SVG, cli.Out, columns, names, charts, results); err != nil {
+		return false, err
+	}
+
+	// output the comparison as JSON, or the charts and comparisons
+	if cli.JSON {
+		err = printJSON(columns, names, results)
+	} else {
+		err = printColumns(columns, names, charts, results, &printOptions{
+			labels:     len(columns) > 1 || cli.Header,
+			noChart:    cli.NoChart,
+			chart:      cli.Chart,
+			width:      cli.Width,
+			height:     cli.Height,
+			format:     cli.Format,
+			unit:       cli.Unit,
+			relative:   cli.Relative,
+			ci:         cli.CI,
+			precision:  cli.Precision,
+			scientific: cli.Scientific,
+		})
+	}
+
+	return regressed(columns, results), err
+}
+
+// confidence returns the confidence level, in the range (0,100), which corresponds to the given
+// significance level, if any. Otherwise, it returns the given confidence level, or 95 if none was
+// given. If both are given, or either is out of range, it returns a usageError.
+func confidence(confidence, alpha float64) (float64, error) {
+	switch {
+	case confidence != 0 && alpha != 0:
+		return 0, usageError("--confidence and --alpha can't be used together")
+	case alpha != 0:
+		if alpha <= 0 || alpha >= 1 {
+			return 0, usageError("--alpha must be between 0 and 1")
+		}
+
+		return (1 - alpha) * 100, nil
+	case confidence != 0:
+		if confidence <= 0 || confidence >= 100 {
+			return 0, usageError("--confidence must be between 0 and 100")
+		}
+
+		return confidence, nil
+	default:
+		return defaultConfidence, nil
+	}
+}
+
+// checkPaths returns a usageError if the control file doesn't exist, or if standard input is given
+// more than once, since it can only be read once. Experiment paths are checked by expandGlobs.
+func checkPaths(controlPath string, experimentPaths []string) error {
+	stdins := 0
+
+	for _, filename := range append([]string{controlPath}, experimentPaths...) {
+		if filename == stdin {
+			stdins++
+		}
+	}
+
+	if stdins > 1 {
+		return usageError("- can only be given once")
+	}
+
+	if controlPath != stdin {
+		if info, err := os.Stat(controlPath); err != nil || info.IsDir() {
+			return usageError(fmt.Sprintf("%s: no such file", controlPath))
+		}
+	}
+
+	return nil
+}
+
+// printOptions control how the charts and comparison tables are printed.
+type printOptions struct {
+	labels     bool
+	noChart    bool
+	chart      string
+	width      int
+	height     int
+	format     string
+	unit       string
+	relative   bool
+	ci         bool
+	precision  int
+	scientific bool
+}
+
+func printColumns(
+	columns []int, names map[int]string, charts map[int]*chart.BoxChart, results map[int][]result,
+	opts *printOptions,
+) error {
+	for i, col := range columns {
+		// label each column if there are more than one or they have names
+		if opts.labels {
+			if i > 0 {
+				fmt.Println()
+			}
+
+			fmt.Printf("%s:\n", columnLabel(col, names))
+		}
+
+		// chart the data
+		switch {
+		case opts.noChart:
+		case opts.chart == chartHistogram:
+			for i := range results[col] {
+				printChart(histChart(&results[col][i], opts.unit), opts.width, opts.height)
+			}
+		default:
+			printChart(charts[col], opts.width, opts.height)
+		}
+
+		// print the comparison
+		if len(results[col]) > 1 {
+			if err := printComparison(results[col], opts); err != nil {
+				return err
+			}
+		}
 	}
+
+	return nil
+}
+
+func writeFiles(
+	svgFilename, csvFilename string, columns []int, names map[int]string,
+	charts map[int]*chart.BoxChart, results map[int][]result,
+) error {
+	if svgFilename != "" {
+		if err := writeSVGs(svgFilename, columns, charts); err != nil {
+			return err
+		}
+	}
+
+	if csvFilename != "" {
+		return writeCSV(csvFilename, columns, names, results)
+	}
+
+	return nil
+}
+
+// A result is the summary of a single data set and, unless it is the control, its difference from
+// the control.
+type result struct {
+	filename   string
+	data       []float64
+	summary    tinystat.Summary
+	difference *tinystat.Difference
+}
+
+func compareColumns(
+	controlFilename string, controlData map[int][]float64,
+	experimentFilenames []string, experimentData map[int]map[string][]float64,
+	columns []int, confidence float64, sortBy string,
+) (map[int][]result, error) {
+	results := make(map[int][]result, len(columns))
+
+	for _, col := range columns {
+		r, err := compare(controlFilename, controlData[col], experimentFilenames, experimentData[col],
+			confidence)
+		if err != nil {
+			return nil, err
+		}
+
+		sortResults(r, sortBy)
+		results[col] = r
+	}
+
+	return results, nil
 }
 
-func printComparison(
+func compare(
 	controlFilename string, controlData []float64,
 	experimentFilenames []string, experimentData map[string][]float64,
 	confidence float64,
-) {
-	t := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintf(t, "File\tN\tMean\tStddev\t\n")
-
+) ([]result, error) {
 	control := tinystat.Summarize(controlData)
-	_, _ = fmt.Fprintf(t, "%s\t%.0f\t%.2f\t%0.2f\t%s\n", path.Base(controlFilename),
-		control.N, control.Mean, control.StdDev(), "(control)")
+	results := []result{{filename: controlFilename, data: controlData, summary: control}}
 
 	for _, filename := range experimentFilenames {
-		experiment := tinystat.Summarize(experimentData[filename])
-		d := tinystat.Compare(control, experiment, confidence)
-		p := strings.TrimLeft(fmt.Sprintf("%.3f", d.PValue), "0")
+		data := experimentData[filename]
+		experiment := tinystat.Summarize(data)
+
+		d, err := tinystat.Compare(control, experiment, confidence)
+		if err != nil {
+			return nil, fmt.Errorf("%s vs. %s: %w", path.Base(controlFilename), path.Base(filename), err)
+		}
+
+		results = append(results, result{filename: filename, data: data, summary: experiment, difference: &d})
+	}
+
+	return results, nil
+}
+
+// regressed returns true if, in any column, any experiment's mean is significantly greater than
+// the control's mean.
+func regressed(columns []int, results map[int][]result) bool {
+	for _, col := range columns {
+		control := results[col][0].summary
+
+		for _, r := range results[col][1:] {
+			if r.difference.Significant() && r.summary.Mean > control.Mean {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sortResults sorts the experiments' results by ascending p-value, descending effect, or ascending
+// mean, leaving the control first. Otherwise, the results are left in the order they were given.
+func sortResults(results []result, by string) {
+	experiments := results[1:]
+
+	var less func(a, b *result) bool
+
+	switch by {
+	case sortP:
+		less = func(a, b *result) bool { return a.difference.PValue < b.difference.PValue }
+	case sortEffect:
+		less = func(a, b *result) bool { return a.difference.Effect > b.difference.Effect }
+	case sortMean:
+		less = func(a, b *result) bool { return a.summary.Mean < b.summary.Mean }
+	default:
+		return
+	}
 
-		var results string
+	sort.SliceStable(experiments, func(i, j int) bool {
+		return less(&experiments[i], &experiments[j])
+	})
+}
+
+// number formats the given value with the table's precision, in scientific notation if requested.
+func (o *printOptions) number(f float64) string {
+	if o.scientific {
+		return fmt.Sprintf("%.*e", o.precision, f)
+	}
+
+	return fmt.Sprintf("%.*f", o.precision, f)
+}
+
+// withUnit formats the given value as a number, followed by the table's unit, if any.
+func (o *printOptions) withUnit(f float64) string {
+	if o.unit == "" {
+		return o.number(f)
+	}
+
+	return o.number(f) + " " + o.unit
+}
+
+// p formats the given p-value with one more digit than the table's precision, stripping the leading
+// zero.
+func (o *printOptions) p(p float64) string {
+	return strings.TrimLeft(fmt.Sprintf("%.*f", o.precision+1, p), "0")
+}
 
-		if d.Significant() {
+// relativeTo returns the difference between the experiment's mean and the control's mean, and the
+// critical value of the difference, as percentages of the control's mean.
+func (o *printOptions) relativeTo(experiment, control float64, d *tinystat.Difference) string {
+	return fmt.Sprintf("%+.*f%% ± %.*f%%",
+		o.precision, (experiment-control)/control*100, o.precision, d.CriticalValue/control*100)
+}
+
+func printComparison(results []result, opts *printOptions) error {
+	control := results[0].summary
+	rows := make([][]string, 0, len(results))
+
+	for i := range results {
+		r := &results[i]
+		var s string
+
+		switch d := r.difference; {
+		case d == nil:
+			s = "(control)"
+		case d.Significant():
 			operator := ">"
-			if experiment.Mean < control.Mean {
+			if r.summary.Mean < control.Mean {
 				operator = "<"
 			}
 
-			results = fmt.Sprintf("(%.2f %s %.2f ± %.2f, p = %s)",
-				experiment.Mean, operator, control.Mean, d.CriticalValue, p)
-		} else {
-			results = fmt.Sprintf("(no difference, p = %s)", p)
+			s = fmt.Sprintf("(%s %s %s ± %s, p = %s)",
+				opts.number(r.summary.Mean), operator, opts.number(control.Mean),
+				opts.number(d.CriticalValue), opts.p(d.PValue))
+		default:
+			s = fmt.Sprintf("(no difference, p = %s)", opts.p(d.PValue))
+		}
+
+		if d := r.difference; d != nil && opts.relative {
+			s = fmt.Sprintf("%s, %s)", strings.TrimSuffix(s, ")"), opts.relativeTo(r.summary.Mean, control.Mean, d))
 		}
 
-		_, _ = fmt.Fprintf(t, "%s\t%.0f\t%.2f\t%0.2f\t%s\n",
-			path.Base(filename), experiment.N, experiment.Mean, experiment.StdDev(), results)
+		rows = append(rows, []string{
+			path.Base(r.filename),
+			fmt.Sprintf("%.0f", r.summary.N),
+			opts.withUnit(r.summary.Mean),
+			opts.withUnit(r.summary.StdDev()),
+			s,
+		})
+
+		if opts.ci {
+			var ci string
+
+			if r.difference != nil {
+				lo, hi := r.difference.ConfidenceInterval()
+				ci = fmt.Sprintf("[ %s, %s ]", opts.number(lo), opts.number(hi))
+			}
+
+			rows[i] = append(rows[i], ci)
+		}
+	}
+
+	if opts.format == formatMarkdown {
+		printMarkdown(rows, opts)
+
+		return nil
+	}
+
+	return printTable(rows, opts)
+}
+
+func printTable(rows [][]string, opts *printOptions) error {
+	t := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(t, "File\tN\tMean\tStddev\t")
+
+	if opts.ci {
+		_, _ = fmt.Fprintf(t, "\tCI")
 	}
 
-	_ = t.Flush()
+	_, _ = fmt.Fprintln(t)
+
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(t, strings.Join(row, "\t"))
+	}
+
+	return t.Flush()
+}
+
+func printMarkdown(rows [][]string, opts *printOptions) {
+	if opts.ci {
+		fmt.Println("| File | N | Mean | Stddev | Result | CI |")
+		fmt.Println("| --- | ---: | ---: | ---: | --- | --- |")
+	} else {
+		fmt.Println("| File | N | Mean | Stddev | Result |")
+		fmt.Println("| --- | ---: | ---: | ---: | --- |")
+	}
+
+	for _, row := range rows {
+		fmt.Printf("| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+// A jsonResult is the JSON representation of a result. Values which aren't finite (e.g., the
+// difference between two data sets with no variance) are represented as null.
+type jsonResult struct {
+	Column     int             `json:"column"`
+	Name       string          `json:"name,omitempty"`
+	File       string          `json:"file"`
+	N          float64         `json:"n"`
+	Mean       *float64        `json:"mean"`
+	StdDev     *float64        `json:"stddev"`
+	Difference *jsonDifference `json:"difference,omitempty"`
+}
+
+// A jsonDifference is the JSON representation of a tinystat.Difference.
+type jsonDifference struct {
+	Effect        *float64 `json:"effect"`
+	Delta         *float64 `json:"delta"`
+	EffectSize    *float64 `json:"effect_size"`
+	HedgesG       *float64 `json:"hedges_g"`
+	CriticalValue *float64 `json:"critical_value"`
+	TValue        *float64 `json:"t_value"`
+	CriticalT     *float64 `json:"critical_t"`
+	PValue        *float64 `json:"p_value"`
+	Alpha         *float64 `json:"alpha"`
+	Beta          *float64 `json:"beta"`
+	Significant   bool     `json:"significant"`
+	ControlN      float64  `json:"control_n"`
+	ExperimentN   float64  `json:"experiment_n"`
+}
+
+func newJSONDifference(d *tinystat.Difference) *jsonDifference {
+	if d == nil {
+		return nil
+	}
+
+	return &jsonDifference{
+		Effect:        finite(d.Effect),
+		Delta:         finite(d.Delta),
+		EffectSize:    finite(d.EffectSize),
+		HedgesG:       finite(d.HedgesG),
+		CriticalValue: finite(d.CriticalValue),
+		TValue:        finite(d.TValue),
+		CriticalT:     finite(d.CriticalT),
+		PValue:        finite(d.PValue),
+		Alpha:         finite(d.Alpha),
+		Beta:          finite(d.Beta),
+		Significant:   d.Significant(),
+		ControlN:      d.ControlN,
+		ExperimentN:   d.ExperimentN,
+	}
+}
+
+// finite returns a pointer to the given value, or nil if it's NaN or infinite, which can't be
+// encoded as JSON.
+func finite(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+
+	return &f
+}
+
+func printJSON(columns []int, names map[int]string, results map[int][]result) error {
+	var out []jsonResult
+
+	for _, col := range columns {
+		for _, r := range results[col] {
+			out = append(out, jsonResult{
+				Column:     col,
+				Name:       names[col],
+				File:       path.Base(r.filename),
+				N:          r.summary.N,
+				Mean:       finite(r.summary.Mean),
+				StdDev:     finite(r.summary.StdDev()),
+				Difference: newJSONDifference(r.difference),
+			})
+		}
+	}
+
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+
+	return e.Encode(out)
+}
+
+func writeCSV(filename string, columns []int, names map[int]string, results map[int][]result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{
+		"column", "filename", "n", "mean", "stddev",
+		"effect", "p_value", "significant", "ci_low", "ci_high",
+	})
+
+	for _, col := range columns {
+		for i := range results[col] {
+			r := &results[col][i]
+			record := []string{
+				columnLabel(col, names),
+				path.Base(r.filename),
+				formatFloat(r.summary.N),
+				formatFloat(r.summary.Mean),
+				formatFloat(r.summary.StdDev()),
+				"", "", "", "", "",
+			}
+
+			if d := r.difference; d != nil {
+				record[5] = formatFloat(d.Effect)
+				record[6] = formatFloat(d.PValue)
+				record[7] = strconv.FormatBool(d.Significant())
+				lo, hi := d.ConfidenceInterval()
+				record[8] = formatFloat(lo)
+				record[9] = formatFloat(hi)
+			}
+
+			_ = w.Write(record)
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// expandGlobs replaces each of the given filenames which doesn't exist with the sorted list of
+// files which match it as a glob pattern (e.g., results/*.csv), dropping any duplicates.
+func expandGlobs(filenames []string) ([]string, error) {
+	expanded := make([]string, 0, len(filenames))
+	seen := make(map[string]bool, len(filenames))
+
+	for _, filename := range filenames {
+		matches := []string{filename}
+
+		if _, err := os.Stat(filename); filename != stdin && os.IsNotExist(err) {
+			matches, err = filepath.Glob(filename)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(matches) == 0 {
+				return nil, usageError(fmt.Sprintf("%s: no such file", filename))
+			}
+
+			sort.Strings(matches)
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+
+				expanded = append(expanded, match)
+			}
+		}
+	}
+
+	return expanded, nil
 }
 
 func readData(
 	controlFilename string, experimentFilenames []string,
-	column int, delimiter string,
-) ([]float64, map[string][]float64, error) {
-	controlData, err := readFile(controlFilename, column, delimiter)
+	columns []int, delimiter string, header, trim bool,
+) (map[int][]float64, map[int]map[string][]float64, map[int]string, error) {
+	controlData, names, err := readFile(controlFilename, columns, delimiter, header)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	experimentData := make(map[string][]float64)
+	if trim {
+		trimOutliers(controlFilename, columns, controlData, names)
+	}
+
+	experimentData := make(map[int]map[string][]float64, len(columns))
+	for _, col := range columns {
+		experimentData[col] = make(map[string][]float64, len(experimentFilenames))
+	}
 
 	for _, filename := range experimentFilenames {
-		expData, err := readFile(filename, column, delimiter)
+		expData, _, err := readFile(filename, columns, delimiter, header)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
+		}
+
+		if trim {
+			trimOutliers(filename, columns, expData, names)
 		}
 
-		experimentData[filename] = expData
+		for col, data := range expData {
+			experimentData[col][filename] = data
+		}
 	}
 
-	return controlData, experimentData, nil
+	return controlData, experimentData, names, nil
 }
 
-func printChart(
-	experimentFilenames []string, controlFilename string, controlData []float64,
-	experimentData map[string][]float64, width, height int,
-) {
-	c := chart.BoxChart{}
-	c.XRange.Fixed(-1, float64(len(experimentFilenames))+1, 1)
-	c.XRange.Category = make([]string, len(experimentFilenames)+1)
-	c.XRange.Category[0] = path.Base(controlFilename)
+// trimOutliers removes the outliers from each column of the file's data, printing the number of
+// measurements which were removed to stderr.
+func trimOutliers(filename string, columns []int, data map[int][]float64, names map[int]string) {
+	for _, col := range columns {
+		var removed []float64
 
-	for i, file := range experimentFilenames {
-		c.XRange.Category[i+1] = path.Base(file)
+		data[col], removed = tinystat.Outliers(data[col])
+
+		label := path.Base(filename)
+		if len(columns) > 1 {
+			label = fmt.Sprintf("%s (%s)", label, columnLabel(col, names))
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "%s: removed %d outliers\n", label, len(removed))
+	}
+}
+
+func columnLabel(col int, names map[int]string) string {
+	if name, ok := names[col]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Column %d", col)
+}
+
+// boxCharts returns a box chart for each column. If the chart is to have a logarithmic scale, all
+// measurements must be positive.
+func boxCharts(
+	columns []int, results map[int][]result, unit string, logScale bool,
+) (map[int]*chart.BoxChart, error) {
+	charts := make(map[int]*chart.BoxChart, len(columns))
+
+	for _, col := range columns {
+		if logScale {
+			if err := checkPositive(results[col]); err != nil {
+				return nil, fmt.Errorf("--log-scale: %w", err)
+			}
+		}
+
+		charts[col] = boxChart(results[col], unit, logScale)
 	}
 
-	c.AddSet(0, controlData, true)
+	return charts, nil
+}
 
-	for i, filename := range experimentFilenames {
-		c.AddSet(float64(i+1), experimentData[filename], true)
+// checkPositive returns an error if any of the results' measurements are not positive.
+func checkPositive(results []result) error {
+	for _, r := range results {
+		for _, v := range r.data {
+			if v <= 0 {
+				return fmt.Errorf("%s: %w", path.Base(r.filename), tinystat.ErrNonPositive)
+			}
+		}
 	}
 
+	return nil
+}
+
+func boxChart(results []result, unit string, logScale bool) *chart.BoxChart {
+	c := chart.BoxChart{}
+	c.YRange.Label = unit
+	c.YRange.Log = logScale
+	c.XRange.Fixed(-1, float64(len(results)), 1)
+	c.XRange.Category = make([]string, len(results))
+
+	for i, r := range results {
+		c.XRange.Category[i] = path.Base(r.filename)
+	}
+
+	for i, r := range results {
+		c.AddSet(float64(i), r.data, true)
+	}
+
+	return &c
+}
+
+// histChart returns a histogram of the data set, titled with its filename.
+func histChart(r *result, unit string) *chart.HistChart {
+	c := chart.HistChart{Title: path.Base(r.filename), Counts: true}
+	c.XRange.Label = unit
+	c.Key.Hide = true
+	c.AddData(path.Base(r.filename), r.data, chart.AutoStyle(0, true))
+
+	return &c
+}
+
+func printChart(c chart.Chart, width, height int) {
 	txt := txtg.New(width, height)
 	c.Plot(txt)
 	fmt.Println(txt)
 }
 
-func readFile(filename string, col int, del string) ([]float64, error) {
-	f, err := os.Open(filename)
+// writeSVGs writes the chart for each column to the given SVG file. If there are multiple columns,
+// each column's chart is written to a separate file, with the column number inserted before the
+// file's extension (e.g., chart.2.svg).
+func writeSVGs(filename string, columns []int, charts map[int]*chart.BoxChart) error {
+	if len(columns) == 1 {
+		return writeSVG(filename, charts[columns[0]])
+	}
+
+	ext := filepath.Ext(filename)
+
+	for _, col := range columns {
+		if err := writeSVG(fmt.Sprintf("%s.%d%s", strings.TrimSuffix(filename, ext), col, ext), charts[col]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeSVG(filename string, c *chart.BoxChart) error {
+	f, err := os.Create(filename)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	defer func() { _ = f.Close() }()
 
-	r := csv.NewReader(f)
-	r.Comma = []rune(del)[0]
+	s := svg.New(f)
+	s.Start(svgWidth, svgHeight)
+	c.Plot(svgg.New(s, svgWidth, svgHeight, "Helvetica", svgFontSize, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+	s.End()
+
+	return f.Close()
+}
+
+func readFile(
+	filename string, columns []int, del string, header bool,
+) (map[int][]float64, map[int]string, error) {
+	f, closeFile, err := openFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer closeFile()
+
+	b := bufio.NewReader(f)
+	r := csv.NewReader(b)
+
+	if del == delimiterAuto {
+		r.Comma = sniffDelimiter(b)
+	} else {
+		r.Comma = []rune(del)[0]
+	}
 
 	records, err := r.ReadAll()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// The CSV reader requires every record to have as many fields as the first.
+	if len(records) > 0 {
+		for _, col := range columns {
+			if col < 0 || col >= len(records[0]) {
+				return nil, nil, fmt.Errorf("%s: no column %d (has %d)", filename, col, len(records[0]))
+			}
+		}
+	}
+
+	var names map[int]string
+
+	if header && len(records) > 0 {
+		names = make(map[int]string, len(columns))
+		for _, col := range columns {
+			names[col] = records[0][col]
+		}
+
+		records = records[1:]
 	}
 
-	data := make([]float64, len(records))
+	data, err := parseRecords(records, columns)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for i, s := range records {
-		n, err := strconv.ParseFloat(s[col], 64)
+	return data, names, nil
+}
+
+// openFile opens the given file, or standard input, transparently decompressing it if it's gzipped.
+// The returned function closes the file.
+func openFile(filename string) (io.Reader, func(), error) {
+	f := os.Stdin
+
+	if filename != stdin {
+		var err error
+
+		f, err = os.Open(filename)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+	}
+
+	closeFile := func() {
+		if f != os.Stdin {
+			_ = f.Close()
+		}
+	}
+
+	b := bufio.NewReader(f)
+	if magic, _ := b.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return b, closeFile, nil
+	}
+
+	z, err := gzip.NewReader(b)
+	if err != nil {
+		closeFile()
+
+		return nil, nil, err
+	}
+
+	return z, func() {
+		_ = z.Close()
+
+		closeFile()
+	}, nil
+}
 
-		data[i] = n
+func parseRecords(records [][]string, columns []int) (map[int][]float64, error) {
+	data := make(map[int][]float64, len(columns))
+
+	for _, record := range records {
+		for _, col := range columns {
+			n, err := strconv.ParseFloat(record[col], 64)
+			if err != nil {
+				return nil, err
+			}
+
+			data[col] = append(data[col], n)
+		}
 	}
 
 	return data, nil
 }
+
+// sniffDelimiter returns the delimiter which appears the same, non-zero number of times in each of
+// the first few lines of the given reader, preferring the one which appears most often. If no
+// delimiter is consistent, or if the most consistent delimiters are tied, it returns a comma.
+func sniffDelimiter(r *bufio.Reader) rune {
+	// Peek returns an error if the input is shorter than the buffer, which is fine.
+	buf, _ := r.Peek(sniffSize)
+
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	if len(lines) > sniffLines {
+		lines = lines[:sniffLines]
+	} else if len(lines) > 1 && len(buf) == sniffSize {
+		// Ignore the last line if it was truncated.
+		lines = lines[:len(lines)-1]
+	}
+
+	best, bestCount, tied := ',', 0, false
+
+	for _, del := range []rune{',', '\t', ';'} {
+		count := strings.Count(lines[0], string(del))
+
+		for _, line := range lines[1:] {
+			if strings.Count(line, string(del)) != count {
+				count = 0
+
+				break
+			}
+		}
+
+		switch {
+		case count > bestCount:
+			best, bestCount, tied = del, count, false
+		case count > 0 && count == bestCount:
+			tied = true
+		}
+	}
+
+	if tied {
+		return ','
+	}
+
+	return best
+}
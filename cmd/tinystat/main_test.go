@@ -1,13 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"unicode"
 
 	"github.com/codahale/gubbins/assert"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 //nolint:paralleltest // shared state
@@ -108,6 +116,672 @@ leopard    6  643.50  240.09  (643.50 > 300.00 ± 293.97, p = .026)
 		))
 }
 
+//nolint:paralleltest // shared state
+func TestMarkdown(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| leopard | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestRelative(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| leopard | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026, +114.50% ± 97.99%) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--relative",
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestSort(t *testing.T) {
+	gecko := tempFile(t, "gecko", "90\n100\n110\n")
+
+	for _, tc := range []struct {
+		by   string
+		want []string
+	}{
+		{"none", []string{"iguana", "leopard", "chameleon", "gecko"}},
+		{"mean", []string{"iguana", "gecko", "chameleon", "leopard"}},
+		{"effect", []string{"iguana", "leopard", "chameleon", "gecko"}},
+		{"p", []string{"iguana", "leopard", "gecko", "chameleon"}},
+	} {
+		out := mainTest(t,
+			"--no-chart",
+			"--sort", tc.by,
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+			"../../examples/chameleon",
+			gecko,
+		)
+
+		var files []string
+
+		for _, line := range strings.Split(out, "\n")[2:] {
+			if fields := strings.Split(line, " | "); len(fields) > 1 {
+				files = append(files, strings.TrimPrefix(fields[0], "| "))
+			}
+		}
+
+		assert.Equal(t, tc.by, tc.want, files)
+	}
+}
+
+//nolint:paralleltest // shared state
+func TestScientific(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| a | 4 | 1.232e-05 | 1.708e-07 | (control) |
+| b | 4 | 1.523e-05 | 2.217e-07 | (1.523e-05 > 1.232e-05 ± 3.479e-07, p = .0000) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--scientific",
+			"--precision", "3",
+			"--format", "markdown",
+			tempFile(t, "a", "0.0000123\n0.0000125\n0.0000121\n0.0000124\n"),
+			tempFile(t, "b", "0.0000153\n0.0000155\n0.0000151\n0.0000150\n"),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestCI(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result | CI |
+| --- | ---: | ---: | ---: | --- | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |  |
+| leopard | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026) | [ 49.53, 637.47 ] |
+| chameleon | 5 | 540.00 | 299.08 | (no difference, p = .178) | [ -136.79, 616.79 ] |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--ci",
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+			"../../examples/chameleon",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestHistogram(t *testing.T) {
+	want := `                 iguana
+
+
+   5.0  +
+        | oooooo
+        | o####o
+        | ooooooooooooo      ooooooo
+     0  +                    ooooooo
+          +------------+------------+
+          0           500         1000
+
+                 leopard
+
+
+   4.0  +
+        |         ooooo
+   2.0  +     ooooo###o
+        |     ooooooooo    oooo
+     0  +
+          +-------+--------+--------+
+          0      500     1000     1.5 k
+
+| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| leopard | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--chart", "histogram",
+			"--width", "40",
+			"--height", "10",
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestAlpha(t *testing.T) {
+	want := mainTest(t,
+		"--no-chart",
+		"--confidence", "95",
+		"../../examples/iguana",
+		"../../examples/chameleon",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--alpha", "0.05",
+			"../../examples/iguana",
+			"../../examples/chameleon",
+			"../../examples/leopard",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestAlphaAndConfidence(t *testing.T) {
+	status, stderr := mainStatus(t,
+		"--confidence", "95",
+		"--alpha", "0.05",
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Status", 2, status)
+	assert.Equal(t, "Stderr", "tinystat: error: --confidence and --alpha can't be used together\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestAlphaOutOfRange(t *testing.T) {
+	status, stderr := mainStatus(t,
+		"--alpha", "1.5",
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Status", 2, status)
+	assert.Equal(t, "Stderr", "tinystat: error: --alpha must be between 0 and 1\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestUnit(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 ns | 238.05 ns | (control) |
+| leopard | 6 | 643.50 ns | 240.09 ns | (643.50 > 300.00 ± 293.97, p = .026) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--unit", "ns",
+			"--format", "markdown",
+			"../../examples/iguana",
+			"../../examples/leopard",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestJSON(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	want := []jsonResult{
+		{
+			File:   "chameleon",
+			N:      5,
+			Mean:   f(540),
+			StdDev: f(299.0819285747636),
+		},
+		{
+			File:   "iguana",
+			N:      7,
+			Mean:   f(300),
+			StdDev: f(238.04761428476166),
+			Difference: &jsonDifference{
+				Effect:        f(240),
+				Delta:         f(-240),
+				EffectSize:    f(0.887925158462644),
+				HedgesG:       f(0.8196232231962868),
+				CriticalValue: f(376.7931758168641),
+				TValue:        f(1.488839530990719),
+				CriticalT:     f(2.337435729848681),
+				PValue:        f(0.17772184154340376),
+				Alpha:         f(0.05),
+				Beta:          f(0.6710594084187584),
+				ControlN:      5,
+				ExperimentN:   7,
+			},
+		},
+	}
+
+	var got []jsonResult
+	if err := json.Unmarshal([]byte(mainTest(t,
+		"--json",
+		"../../examples/chameleon",
+		"../../examples/iguana",
+	)), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Output", want, got, cmpopts.EquateApprox(0.001, 0.001))
+}
+
+//nolint:paralleltest // shared state
+func TestJSONNonFinite(t *testing.T) {
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(mainTest(t,
+		"--json",
+		tempFile(t, "a", "1\n1\n1\n"),
+		tempFile(t, "b", "1\n1\n1\n"),
+	)), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	d, ok := got[1]["difference"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("no difference in %v", got[1])
+	}
+
+	assert.Equal(t, "Effect", 0.0, d["effect"])
+	assert.Equal(t, "EffectSize", nil, d["effect_size"])
+	assert.Equal(t, "PValue", nil, d["p_value"])
+}
+
+//nolint:paralleltest // shared state
+func TestStdin(t *testing.T) {
+	want := `File       N  Mean    Stddev
+-          7  300.00  238.05  (control)
+chameleon  5  540.00  299.08  (no difference, p = .178)
+`
+
+	data, err := ioutil.ReadFile("../../examples/iguana")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = w.Close()
+
+	oldStdin := os.Stdin
+
+	defer func() {
+		os.Stdin = oldStdin
+	}()
+
+	os.Stdin = r
+
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"-",
+			"../../examples/chameleon",
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestColumns(t *testing.T) {
+	want := `Column 0:
+File            N  Mean    Stddev
+control.csv     7  300.00  238.05  (control)
+experiment.csv  5  540.00  299.08  (no difference, p = .178)
+
+Column 1:
+File            N  Mean     Stddev
+control.csv     7  600.00   476.10  (control)
+experiment.csv  5  1080.00  598.16  (no difference, p = .178)
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--columns", "0,1",
+			tempFile(t, "control.csv", "50,100\n200,400\n150,300\n400,800\n750,1500\n400,800\n150,300\n"),
+			tempFile(t, "experiment.csv", "150,300\n400,800\n720,1440\n500,1000\n930,1860\n"),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestColumnOutOfRange(t *testing.T) {
+	status, stderr := mainStatus(t,
+		"--columns", "0,3",
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Status", 255, status)
+	assert.Equal(t, "Stderr", "../../examples/iguana: no column 3 (has 1)\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestHeader(t *testing.T) {
+	want := `score:
+File            N  Mean    Stddev
+control.csv     7  300.00  238.05  (control)
+experiment.csv  5  540.00  299.08  (no difference, p = .178)
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--header",
+			"--column", "1",
+			tempFile(t, "control.csv", "run,score\n1,50\n2,200\n3,150\n4,400\n5,750\n6,400\n7,150\n"),
+			tempFile(t, "experiment.csv", "run,score\n1,150\n2,400\n3,720\n4,500\n5,930\n"),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestOut(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "results.csv")
+
+	mainTest(t,
+		"--no-chart",
+		"--out", out,
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Header",
+		[]string{
+			"column", "filename", "n", "mean", "stddev",
+			"effect", "p_value", "significant", "ci_low", "ci_high",
+		},
+		records[0])
+	assert.Equal(t, "Control",
+		[]string{"Column 0", "iguana", "7", "300", records[1][4], "", "", "", "", ""},
+		records[1])
+	assert.Equal(t, "Experiment",
+		[]string{"Column 0", "leopard", "6", "643.5", "true"},
+		[]string{records[2][0], records[2][1], records[2][2], records[2][3], records[2][7]})
+}
+
+//nolint:paralleltest // shared state
+func TestSVG(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "chart.svg")
+
+	mainTest(t,
+		"--no-chart",
+		"--svg", out,
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "SVG", true, strings.Contains(string(b), "<svg"))
+}
+
+//nolint:paralleltest // shared state
+func TestDelimiterAuto(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| control.tsv | 7 | 300.00 | 238.05 | (control) |
+| experiment.tsv | 5 | 540.00 | 299.08 | (no difference, p = .178) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--column", "1",
+			"--format", "markdown",
+			tempFile(t, "control.tsv", "1\t50\n2\t200\n3\t150\n4\t400\n5\t750\n6\t400\n7\t150\n"),
+			tempFile(t, "experiment.tsv", "1\t150\n2\t400\n3\t720\n4\t500\n5\t930\n"),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestFailOnRegression(t *testing.T) {
+	for _, tc := range []struct {
+		control, experiment string
+		want                int
+	}{
+		{"iguana", "leopard", 1},
+		{"leopard", "iguana", 0},
+		{"iguana", "chameleon", 0},
+	} {
+		status, _ := mainStatus(t,
+			"--no-chart",
+			"--fail-on-regression",
+			filepath.Join("..", "..", "examples", tc.control),
+			filepath.Join("..", "..", "examples", tc.experiment),
+		)
+
+		assert.Equal(t, tc.control+" vs. "+tc.experiment, tc.want, status)
+	}
+}
+
+//nolint:paralleltest // shared state
+func TestUsageError(t *testing.T) {
+	status, _ := mainStatus(t,
+		"--fail-on-regression",
+		"--no-such-flag",
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Status", 2, status)
+}
+
+//nolint:paralleltest // shared state
+func TestLogScaleNonPositive(t *testing.T) {
+	status, stderr := mainStatus(t,
+		"--log-scale",
+		"../../examples/iguana",
+		tempFile(t, "zero", "0\n1\n2\n"),
+	)
+
+	assert.Equal(t, "Status", 255, status)
+	assert.Equal(t, "Error", "--log-scale: zero: measurements must be positive\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestLogScaleNoChart(t *testing.T) {
+	for _, flag := range []string{"--no-chart", "--json"} {
+		status, _ := mainStatus(t,
+			"--log-scale",
+			flag,
+			"../../examples/iguana",
+			tempFile(t, "zero", "0\n1\n2\n"),
+		)
+
+		assert.Equal(t, flag, 0, status)
+	}
+}
+
+//nolint:paralleltest // shared state
+func TestLogScaleHistogram(t *testing.T) {
+	status, stderr := mainStatus(t,
+		"--log-scale",
+		"--chart", "histogram",
+		"../../examples/iguana",
+		"../../examples/leopard",
+	)
+
+	assert.Equal(t, "Status", 2, status)
+	assert.Equal(t, "Error", "tinystat: error: --log-scale can't be used with --chart histogram\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestGzip(t *testing.T) {
+	data, err := ioutil.ReadFile("../../examples/leopard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+
+	z := gzip.NewWriter(&b)
+	if _, err := z.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| leopard.gz | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--format", "markdown",
+			"../../examples/iguana",
+			tempFile(t, "leopard.gz", b.String()),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestStdinTwice(t *testing.T) {
+	status, stderr := mainStatus(t, "-", "-")
+
+	assert.Equal(t, "Status", 2, status)
+	assert.Equal(t, "Stderr", "tinystat: error: - can only be given once\n", stderr)
+}
+
+//nolint:paralleltest // shared state
+func TestMissingFile(t *testing.T) {
+	for _, args := range [][]string{
+		{"../../examples/nope", "../../examples/iguana"},
+		{"../../examples/iguana", "../../examples/nope"},
+	} {
+		status, stderr := mainStatus(t, args...)
+
+		assert.Equal(t, "Status", 2, status)
+		assert.Equal(t, "Stderr", "tinystat: error: ../../examples/nope: no such file\n", stderr)
+	}
+}
+
+//nolint:paralleltest // shared state
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range map[string]string{
+		"c.csv": "150\n400\n720\n500\n930\n",
+		"a.csv": "353\n574\n495\n1057\n664\n718\n",
+		"b.csv": "50\n200\n150\n400\n750\n400\n150\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| b.csv | 7 | 300.00 | 238.05 | (no difference, p = 1.000) |
+| a.csv | 6 | 643.50 | 240.09 | (643.50 > 300.00 ± 293.97, p = .026) |
+| c.csv | 5 | 540.00 | 299.08 | (no difference, p = .178) |
+`
+	assert.Equal(t, "Output", want,
+		mainTest(t,
+			"--no-chart",
+			"--format", "markdown",
+			"../../examples/iguana",
+			filepath.Join(dir, "b.csv"),
+			filepath.Join(dir, "*.csv"),
+		))
+}
+
+//nolint:paralleltest // shared state
+func TestTrimOutliers(t *testing.T) {
+	want := `| File | N | Mean | Stddev | Result |
+| --- | ---: | ---: | ---: | --- |
+| iguana | 7 | 300.00 | 238.05 | (control) |
+| outlier | 5 | 540.00 | 299.08 | (no difference, p = .178) |
+`
+	args := []string{
+		"--no-chart",
+		"--trim-outliers",
+		"--format", "markdown",
+		"../../examples/iguana",
+		tempFile(t, "outlier", "150\n400\n720\n500\n930\n100000\n"),
+	}
+
+	assert.Equal(t, "Output", want, mainTest(t, args...))
+
+	status, stderr := mainStatus(t, args...)
+	assert.Equal(t, "Status", 0, status)
+	assert.Equal(t, "Stderr", "iguana: removed 0 outliers\noutlier: removed 1 outliers\n", stderr)
+}
+
+func tempFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(filename, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+// TestMain runs main instead of the tests if TINYSTAT_ARGS is set, allowing mainStatus to check
+// the exit status of main in a subprocess.
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv("TINYSTAT_ARGS"); ok {
+		os.Args = append([]string{"tinystat"}, strings.Split(args, "\n")...)
+
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// mainStatus runs main with the given arguments in a subprocess, returning its exit status and
+// anything it wrote to stderr.
+func mainStatus(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), "TINYSTAT_ARGS="+strings.Join(args, "\n"))
+	cmd.Stderr = &stderr
+
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), stderr.String()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	return 0, stderr.String()
+}
+
 func mainTest(t *testing.T, args ...string) string {
 	t.Helper()
 
@@ -0,0 +1,45 @@
+package tinystat
+
+import (
+	"math"
+	"sort"
+)
+
+// An ECDF is the empirical cumulative distribution function of a data set.
+type ECDF struct {
+	sorted []float64
+}
+
+// NewECDF returns the empirical cumulative distribution function of the given data set.
+func NewECDF(data []float64) ECDF {
+	return ECDF{sorted: sortedCopy(data)}
+}
+
+// At returns the fraction of measurements which are less than or equal to x. If the data set is
+// empty, it returns NaN.
+func (e ECDF) At(x float64) float64 {
+	if len(e.sorted) == 0 {
+		return math.NaN()
+	}
+
+	n := sort.Search(len(e.sorted), func(i int) bool { return e.sorted[i] > x })
+
+	return float64(n) / float64(len(e.sorted))
+}
+
+// Quantile returns the inverse of the ECDF at p, which must be in the range [0, 1]: the smallest
+// measurement x for which At(x) is at least p. If the data set is empty, it returns NaN.
+func (e ECDF) Quantile(p float64) float64 {
+	if p < 0 || p > 1 {
+		panic("p must be between 0 and 1")
+	}
+
+	if len(e.sorted) == 0 {
+		return math.NaN()
+	}
+
+	n := len(e.sorted)
+	i := sort.Search(n, func(i int) bool { return float64(i+1)/float64(n) >= p })
+
+	return e.sorted[i]
+}
@@ -0,0 +1,76 @@
+package tinystat_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/tinystat"
+)
+
+func TestECDFAt(t *testing.T) {
+	t.Parallel()
+
+	e := tinystat.NewECDF(iguana)
+
+	for x, want := range map[float64]float64{
+		0:    0,
+		49:   0,
+		50:   1.0 / 7,
+		149:  1.0 / 7,
+		150:  3.0 / 7,
+		300:  4.0 / 7,
+		400:  6.0 / 7,
+		750:  1,
+		1000: 1,
+	} {
+		assert.Equal(t, "At", want, e.At(x), epsilon)
+	}
+}
+
+func TestECDFQuantile(t *testing.T) {
+	t.Parallel()
+
+	e := tinystat.NewECDF(iguana)
+
+	for p, want := range map[float64]float64{
+		0:       50,
+		1.0 / 7: 50,
+		0.2:     150,
+		0.5:     200,
+		6.0 / 7: 400,
+		0.9:     750,
+		1:       750,
+	} {
+		assert.Equal(t, "Quantile", want, e.Quantile(p))
+	}
+}
+
+func TestECDFQuantileRounding(t *testing.T) {
+	t.Parallel()
+
+	data := make([]float64, 100)
+	for i := range data {
+		data[i] = float64(i + 1)
+	}
+
+	e := tinystat.NewECDF(data)
+
+	// 0.07*100 is slightly more than 7, which mustn't round up to the 8th measurement.
+	assert.Equal(t, "Quantile", 7.0, e.Quantile(0.07))
+
+	for k := 1; k <= 100; k++ {
+		p := float64(k) / 100
+		assert.Equal(t, "At", true, e.At(e.Quantile(p)) >= p)
+		assert.Equal(t, "Smallest", true, e.At(e.Quantile(p)-1) < p)
+	}
+}
+
+func TestECDFEmpty(t *testing.T) {
+	t.Parallel()
+
+	e := tinystat.NewECDF(nil)
+
+	assert.Equal(t, "At", true, math.IsNaN(e.At(1)))
+	assert.Equal(t, "Quantile", true, math.IsNaN(e.Quantile(0.5)))
+}
@@ -3,6 +3,7 @@ module github.com/codahale/tinystat
 go 1.16
 
 require (
+	github.com/ajstarks/svgo v0.0.0-20181006003313-6ce6a3bcf6cd
 	github.com/alecthomas/kong v0.2.16
 	github.com/codahale/gubbins v0.0.1
 	github.com/google/go-cmp v0.5.5
@@ -0,0 +1,70 @@
+package tinystat
+
+import "math"
+
+// KSTest performs a one-sample Kolmogorov-Smirnov test of the null hypothesis that the given data
+// set was drawn from the distribution with the given CDF (e.g., distuv.LogNormal{...}.CDF),
+// returning the D statistic and its asymptotic p-value. If the data set is empty, or contains NaN,
+// or the CDF returns NaN, both are NaN.
+func KSTest(data []float64, cdf func(float64) float64) (d, pValue float64) {
+	if len(data) == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	// Find the largest distance between the empirical CDF, on either side of each measurement, and
+	// the hypothesized CDF.
+	x := sortedCopy(data)
+	n := float64(len(x))
+
+	for i, v := range x {
+		f := cdf(v)
+		d = math.Max(d, math.Max(float64(i+1)/n-f, f-float64(i)/n))
+	}
+
+	return d, 1 - kolmogorovCDF(math.Sqrt(n)*d)
+}
+
+// kolmogorovCDF returns the CDF of the Kolmogorov distribution, which is the limiting distribution
+// of sqrt(n)*D, at x.
+func kolmogorovCDF(x float64) float64 {
+	const (
+		tolerance = 1e-12
+		maxTerms  = 100
+	)
+
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+
+	if x <= 0 {
+		return 0
+	}
+
+	// For small values, use Jacobi's theta function form, which converges quickly.
+	if x < 1 {
+		z := -(math.Pi * math.Pi / 8) / (x * x)
+		s := 0.0
+
+		for k := 1.0; k < 200; k += 2 {
+			s += math.Exp(k * k * z)
+		}
+
+		return math.Sqrt(2*math.Pi) / x * s
+	}
+
+	// Otherwise, use the alternating series, which converges within a few terms.
+	z := -2 * x * x
+	p, sign := 1.0, -1.0
+
+	for k := 1.0; k <= maxTerms; k++ {
+		term := 2 * sign * math.Exp(z*k*k)
+		p += term
+		sign = -sign
+
+		if math.Abs(term) < tolerance {
+			break
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,72 @@
+package tinystat_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/tinystat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func uniformCDF(x float64) float64 {
+	return math.Max(0, math.Min(1, x))
+}
+
+func TestKSTest(t *testing.T) {
+	t.Parallel()
+
+	// ks.test(c(.61, .75, .81, .88, .9, .95, .97, .99, .93, .85), "punif", exact = FALSE)
+	d, p := tinystat.KSTest([]float64{.61, .75, .81, .88, .9, .95, .97, .99, .93, .85}, uniformCDF)
+
+	assert.Equal(t, "D", 0.65, d, epsilon)
+	assert.Equal(t, "p", 0.0004278, p, epsilon)
+}
+
+func TestKSTestUniform(t *testing.T) {
+	t.Parallel()
+
+	// ks.test(c(.05, .12, .31, .44, .58, .61, .77, .9), "punif", exact = FALSE)
+	d, p := tinystat.KSTest([]float64{.05, .12, .31, .44, .58, .61, .77, .9}, uniformCDF)
+
+	assert.Equal(t, "D", 0.14, d, epsilon)
+	assert.Equal(t, "p", 0.9976, p, epsilon)
+}
+
+func TestKSTestNormal(t *testing.T) {
+	t.Parallel()
+
+	data := make([]float64, 20)
+	for i := range data {
+		data[i] = (float64(i) + .5) / float64(len(data))
+	}
+
+	// ks.test((0:19 + .5) / 20, "pnorm", exact = FALSE)
+	d, p := tinystat.KSTest(data, distuv.UnitNormal.CDF)
+
+	assert.Equal(t, "D", 0.509972518195238, d, epsilon)
+	assert.Equal(t, "p", 6.069000158404059e-05, p, epsilon)
+}
+
+func TestKSTestEmpty(t *testing.T) {
+	t.Parallel()
+
+	d, p := tinystat.KSTest(nil, uniformCDF)
+
+	assert.Equal(t, "D", true, math.IsNaN(d))
+	assert.Equal(t, "p", true, math.IsNaN(p))
+}
+
+func TestKSTestNaN(t *testing.T) {
+	t.Parallel()
+
+	d, p := tinystat.KSTest([]float64{1, 2, math.NaN()}, distuv.UnitNormal.CDF)
+
+	assert.Equal(t, "D", true, math.IsNaN(d))
+	assert.Equal(t, "p", true, math.IsNaN(p))
+
+	d, p = tinystat.KSTest([]float64{1, 2, 3}, func(float64) float64 { return math.NaN() })
+
+	assert.Equal(t, "D", true, math.IsNaN(d))
+	assert.Equal(t, "p", true, math.IsNaN(p))
+}
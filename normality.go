@@ -0,0 +1,234 @@
+package tinystat
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var (
+	// ErrSampleSize is returned when a data set has too few or too many measurements for a test.
+	ErrSampleSize = errors.New("sample size must be between 3 and 5000")
+
+	// ErrZeroRange is returned when all measurements in a data set are identical.
+	ErrZeroRange = errors.New("all measurements are identical")
+)
+
+// ShapiroWilk performs a Shapiro-Wilk test of the null hypothesis that the given data set was drawn
+// from a normal distribution, returning the W statistic and its p-value. The coefficients and the
+// p-value are calculated using Royston's approximation (Algorithm AS R94), and the data set must
+// have between 3 and 5000 measurements.
+func ShapiroWilk(data []float64) (w, pValue float64, err error) {
+	n := len(data)
+	if n < 3 || n > 5000 {
+		return 0, 0, ErrSampleSize
+	}
+
+	x := sortedCopy(data)
+	if x[n-1]-x[0] == 0 {
+		return 0, 0, ErrZeroRange
+	}
+
+	// Calculate W as the ratio of the squared linear combination of the order statistics to the
+	// sum of squares about the mean.
+	a := shapiroWilkCoefficients(n)
+	b := 0.0
+
+	for i := range a {
+		b += a[i] * (x[n-1-i] - x[i])
+	}
+
+	s := Summarize(x)
+	w = b * b / (s.Variance * (s.N - 1))
+
+	return w, shapiroWilkPValue(w, n), nil
+}
+
+// IsNormal returns true if a Shapiro-Wilk test fails to reject the null hypothesis that the given
+// data set was drawn from a normal distribution at the given significance level.
+func IsNormal(data []float64, alpha float64) bool {
+	_, p, err := ShapiroWilk(data)
+
+	return err == nil && p >= alpha
+}
+
+// AndersonDarling performs an Anderson-Darling test of the null hypothesis that the given data set
+// was drawn from a normal distribution with unknown mean and variance, returning the A² statistic
+// and its p-value. The p-value is approximated from A² with D'Agostino and Stephens' small-sample
+// correction. The data set must have at least 8 measurements, and they must not all be identical;
+// otherwise, both values are NaN.
+func AndersonDarling(data []float64) (a2, pValue float64) {
+	n := len(data)
+	if n < 8 {
+		return math.NaN(), math.NaN()
+	}
+
+	x := sortedCopy(data)
+	if x[n-1]-x[0] == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	// Standardize the data and compare the hypothesized CDF at each pair of order statistics. The
+	// logs are calculated directly so that measurements far in the tails don't underflow.
+	s := Summarize(x)
+	mean, stdDev := s.Mean, s.StdDev()
+	h := 0.0
+
+	for i := range x {
+		h += float64(2*i+1) * (logNormalCDF((x[i]-mean)/stdDev) + logNormalCDF((mean-x[n-1-i])/stdDev))
+	}
+
+	an := float64(n)
+	a2 = -an - h/an
+
+	// Correct A² for the sample size and approximate its p-value.
+	aa := a2 * (1 + 0.75/an + 2.25/(an*an))
+
+	switch {
+	case aa < 0.2:
+		pValue = 1 - math.Exp(poly(aa, -13.436, 101.14, -223.73))
+	case aa < 0.34:
+		pValue = 1 - math.Exp(poly(aa, -8.318, 42.796, -59.938))
+	case aa < 0.6:
+		pValue = math.Exp(poly(aa, 0.9177, -4.279, -1.38))
+	case aa < adVertex:
+		pValue = math.Exp(poly(aa, 1.2937, -5.709, 0.0186))
+	default:
+		// Past its vertex, the last approximation turns back upwards.
+		pValue = 0
+	}
+
+	return a2, math.Max(0, math.Min(1, pValue))
+}
+
+// adVertex is the vertex of the last of the Anderson-Darling p-value approximations.
+const adVertex = 5.709 / (2 * 0.0186)
+
+// logNormalCDF returns the log of the standard normal CDF at z, using an asymptotic expansion far in
+// the lower tail, where the CDF itself would underflow.
+func logNormalCDF(z float64) float64 {
+	switch {
+	case z > 0:
+		return math.Log1p(-0.5 * math.Erfc(z/math.Sqrt2))
+	case z > -30:
+		return math.Log(0.5 * math.Erfc(-z/math.Sqrt2))
+	default:
+		z2 := z * z
+
+		return -z2/2 - math.Log(-z) - math.Log(2*math.Pi)/2 + math.Log(1-1/z2+3/(z2*z2))
+	}
+}
+
+// QQPlot returns the points of a quantile-quantile plot of the given data set against the
+// distribution with the given quantile function (e.g., distuv.LogNormal{...}.Quantile): the
+// theoretical quantiles of the distribution at each measurement's plotting position, and the sorted
+// measurements. If the data set was drawn from the distribution, the points will fall close to a
+// straight line.
+func QQPlot(data []float64, quantile func(float64) float64) (theoretical, sample []float64) {
+	sample = sortedCopy(data)
+	theoretical = make([]float64, len(sample))
+
+	// Use the same plotting positions as R's ppoints.
+	n := float64(len(sample))
+
+	a := 0.5
+	if len(sample) <= 10 {
+		a = 0.375
+	}
+
+	for i := range theoretical {
+		theoretical[i] = quantile((float64(i+1) - a) / (n + 1 - 2*a))
+	}
+
+	return theoretical, sample
+}
+
+// NormalQQ returns the points of a quantile-quantile plot of the given data set against the
+// standard normal distribution.
+func NormalQQ(data []float64) (theoretical, sample []float64) {
+	return QQPlot(data, distuv.UnitNormal.Quantile)
+}
+
+// shapiroWilkCoefficients returns the first half of the antisymmetric coefficients for the
+// Shapiro-Wilk test of a data set of the given size.
+func shapiroWilkCoefficients(n int) []float64 {
+	a := make([]float64, n/2)
+
+	if n == 3 {
+		a[0] = math.Sqrt(0.5)
+
+		return a
+	}
+
+	// Approximate the expected values of the standard normal order statistics.
+	an := float64(n)
+	m := make([]float64, len(a))
+	summ2 := 0.0
+
+	for i := range m {
+		m[i] = distuv.UnitNormal.Quantile((float64(i+1) - 0.375) / (an + 0.25))
+		summ2 += 2 * m[i] * m[i]
+	}
+
+	ssumm2 := math.Sqrt(summ2)
+	rsn := 1 / math.Sqrt(an)
+
+	// Approximate the two most extreme coefficients with polynomials, and normalize the rest.
+	a[0] = poly(rsn, 0, .221157, -.147981, -2.07119, 4.434685, -2.706056) - m[0]/ssumm2
+	fac := math.Sqrt((summ2 - 2*m[0]*m[0]) / (1 - 2*a[0]*a[0]))
+	i := 1
+
+	if n > 5 {
+		a[1] = poly(rsn, 0, .042981, -.293762, -1.752461, 5.682633, -3.582633) - m[1]/ssumm2
+		fac = math.Sqrt((summ2 - 2*m[0]*m[0] - 2*m[1]*m[1]) / (1 - 2*a[0]*a[0] - 2*a[1]*a[1]))
+		i = 2
+	}
+
+	for ; i < len(a); i++ {
+		a[i] = -m[i] / fac
+	}
+
+	return a
+}
+
+// shapiroWilkPValue returns the p-value of the given W statistic for a data set of the given size.
+func shapiroWilkPValue(w float64, n int) float64 {
+	// For three measurements, the distribution of W is known exactly.
+	if n == 3 {
+		return math.Max(0, 6/math.Pi*(math.Asin(math.Sqrt(w))-math.Pi/3))
+	}
+
+	// Otherwise, normalize W and approximate its upper tail with a normal distribution.
+	an := float64(n)
+	y := math.Log(1 - w)
+
+	var mu, sigma float64
+
+	if n <= 11 {
+		gamma := poly(an, -2.273, .459)
+		if y >= gamma {
+			return 0
+		}
+
+		y = -math.Log(gamma - y)
+		mu = poly(an, .544, -.39978, .025054, -6.714e-4)
+		sigma = math.Exp(poly(an, 1.3822, -.77857, .062767, -.0020322))
+	} else {
+		ln := math.Log(an)
+		mu = poly(ln, -1.5861, -.31082, -.083751, .0038915)
+		sigma = math.Exp(poly(ln, -.4803, -.082676, .0030302))
+	}
+
+	return distuv.Normal{Mu: mu, Sigma: sigma}.Survival(y)
+}
+
+// poly evaluates the polynomial with the given coefficients, in increasing order of degree, at x.
+func poly(x float64, c ...float64) float64 {
+	r := 0.0
+	for i := len(c) - 1; i >= 0; i-- {
+		r = r*x + c[i]
+	}
+
+	return r
+}
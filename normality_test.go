@@ -0,0 +1,125 @@
+package tinystat_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/tinystat"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestShapiroWilk(t *testing.T) {
+	t.Parallel()
+
+	// shapiro.test(c(148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236))
+	w, p, err := tinystat.ShapiroWilk([]float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "W", 0.78881, w, epsilon)
+	assert.Equal(t, "p", 0.006704, p, epsilon)
+}
+
+func TestShapiroWilkThree(t *testing.T) {
+	t.Parallel()
+
+	// shapiro.test(c(1, 2, 4))
+	w, p, err := tinystat.ShapiroWilk([]float64{1, 2, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "W", 0.96429, w, epsilon)
+	assert.Equal(t, "p", 0.6369, p, epsilon)
+}
+
+func TestShapiroWilkErrors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := tinystat.ShapiroWilk([]float64{1, 2})
+	assert.Equal(t, "Error", tinystat.ErrSampleSize, err, cmpopts.EquateErrors())
+
+	_, _, err = tinystat.ShapiroWilk([]float64{1, 1, 1})
+	assert.Equal(t, "Error", tinystat.ErrZeroRange, err, cmpopts.EquateErrors())
+}
+
+func TestAndersonDarling(t *testing.T) {
+	t.Parallel()
+
+	// nortest::ad.test(c(148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236))
+	a2, p := tinystat.AndersonDarling([]float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236})
+
+	assert.Equal(t, "A2", 0.94677, a2, epsilon)
+	assert.Equal(t, "p", 0.01045, p, epsilon)
+}
+
+func TestAndersonDarlingNormal(t *testing.T) {
+	t.Parallel()
+
+	a2, p := tinystat.AndersonDarling(append(append([]float64{}, iguana...), leopard...))
+
+	assert.Equal(t, "A2", 0.21963, a2, epsilon)
+	assert.Equal(t, "p", 0.79148, p, epsilon)
+}
+
+func TestAndersonDarlingLogNormal(t *testing.T) {
+	t.Parallel()
+
+	// Generate grossly non-normal, heavy-tailed data from a log-normal distribution.
+	data := make([]float64, 2000)
+	for i := range data {
+		data[i] = math.Exp(2 * distuv.UnitNormal.Quantile((float64(i)+0.5)/float64(len(data))))
+	}
+
+	a2, p := tinystat.AndersonDarling(data)
+
+	assert.Equal(t, "Finite", false, math.IsInf(a2, 0) || math.IsNaN(a2))
+	assert.Equal(t, "A2", true, a2 > 150)
+	assert.Equal(t, "p", 0.0, p)
+}
+
+func TestAndersonDarlingInvalid(t *testing.T) {
+	t.Parallel()
+
+	a2, p := tinystat.AndersonDarling(chameleon)
+	assert.Equal(t, "Small", []bool{true, true}, []bool{math.IsNaN(a2), math.IsNaN(p)})
+
+	a2, p = tinystat.AndersonDarling([]float64{1, 1, 1, 1, 1, 1, 1, 1})
+	assert.Equal(t, "Identical", []bool{true, true}, []bool{math.IsNaN(a2), math.IsNaN(p)})
+}
+
+func TestNormalQQ(t *testing.T) {
+	t.Parallel()
+
+	// qqnorm(c(1, 2, 4))$x
+	theoretical, sample := tinystat.NormalQQ([]float64{4, 1, 2})
+
+	assert.Equal(t, "Theoretical", []float64{-0.8694238, 0, 0.8694238}, theoretical, epsilon)
+	assert.Equal(t, "Sample", []float64{1, 2, 4}, sample)
+}
+
+func TestNormalQQLinear(t *testing.T) {
+	t.Parallel()
+
+	// Generate perfectly normal data with a mean of 10 and a standard deviation of 2.
+	data := make([]float64, 50)
+	for i := range data {
+		data[i] = 10 + 2*distuv.UnitNormal.Quantile((float64(i)+0.5)/float64(len(data)))
+	}
+
+	theoretical, sample := tinystat.NormalQQ(data)
+
+	assert.Equal(t, "Correlation", true, stat.Correlation(theoretical, sample, nil) > 0.999)
+}
+
+func TestIsNormal(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "IsNormal", true, tinystat.IsNormal(chameleon, 0.05))
+	assert.Equal(t, "IsNormal", false,
+		tinystat.IsNormal([]float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236}, 0.05))
+}
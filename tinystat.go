@@ -1,9 +1,14 @@
 // Package tinystat provides the ability to compare data sets using Welch's t-test at various levels
 // of confidence.
+//
+// For most uses, CompareData is the simplest entry point. For more control, data sets can be
+// summarized with Summarize (or one of its variants) and the summaries compared with Compare.
 package tinystat
 
 import (
+	"errors"
 	"math"
+	"sort"
 
 	"gonum.org/v1/gonum/stat"
 	"gonum.org/v1/gonum/stat/distuv"
@@ -13,7 +18,17 @@ import (
 type Summary struct {
 	N        float64 // N is the number of measurements in the set.
 	Mean     float64 // Mean is the arithmetic mean of the measurements.
-	Variance float64 // Variance is the sample variance of the data set.
+	Variance float64 // Variance is the sample variance of the data set, or NaN if N < 2.
+
+	// Skewness is the sample skewness of the data set, and is NaN for fewer than three
+	// measurements. A large absolute skewness indicates the data set is not normally distributed.
+	// It is only calculated by Summarize, and is NaN otherwise.
+	Skewness float64
+
+	// Kurtosis is the sample excess kurtosis of the data set, and is NaN for fewer than four
+	// measurements. A large kurtosis indicates the data set has heavier tails than a normal
+	// distribution. It is only calculated by Summarize, and is NaN otherwise.
+	Kurtosis float64
 }
 
 // StdDev returns the standard deviation of the sample.
@@ -26,11 +41,300 @@ func (s *Summary) StdErr() float64 {
 	return stat.StdErr(s.StdDev(), s.N)
 }
 
-// Summarize analyzes the given data set and returns a Summary.
+// Summarize analyzes the given data set and returns a Summary. If the data set is empty, the mean
+// is NaN; if it has fewer than two measurements, the variance is NaN.
 func Summarize(data []float64) Summary {
-	m, v := stat.MeanVariance(data, nil)
+	if len(data) == 0 {
+		nan := math.NaN()
+
+		return Summary{Mean: nan, Variance: nan, Skewness: nan, Kurtosis: nan}
+	}
+
+	// Shift the data by its first measurement, which is a rough estimate of the mean, to avoid losing
+	// precision when the measurements are large relative to their spread.
+	n, k := float64(len(data)), data[0]
+	sum := 0.0
+
+	for _, x := range data {
+		sum += x - k
+	}
+
+	m := sum / n
+
+	// Calculate the variance with the corrected two-pass algorithm.
+	ss, c := 0.0, 0.0
+
+	for _, x := range data {
+		d := x - k - m
+		ss += d * d
+		c += d
+	}
+
+	v := (ss - c*c/n) / (n - 1)
+	if len(data) == 1 {
+		v = math.NaN()
+	}
+
+	skew := stat.Skew(data, nil)
+	if len(data) < 3 {
+		skew = math.NaN()
+	}
+
+	return Summary{
+		Mean:     m + k,
+		Variance: v,
+		N:        n,
+		Skewness: skew,
+		Kurtosis: stat.ExKurtosis(data, nil),
+	}
+}
+
+// SummarizeSafe analyzes the given data set and returns a Summary, like Summarize. If the data set
+// is empty, ErrNoData is returned; if it has a single measurement, the variance is NaN.
+func SummarizeSafe(data []float64) (Summary, error) {
+	if len(data) == 0 {
+		return Summary{}, ErrNoData
+	}
+
+	return Summarize(data), nil
+}
+
+var (
+	// ErrInsufficientData is returned when a data set has too few measurements to estimate its
+	// variance.
+	ErrInsufficientData = errors.New("at least two measurements are required")
+
+	// ErrNoData is returned when a data set has no measurements to summarize.
+	ErrNoData = errors.New("at least one measurement is required")
+
+	// ErrNonPositive is returned when a data set which must be strictly positive is not.
+	ErrNonPositive = errors.New("measurements must be positive")
+
+	// ErrWeightsLength is returned when a data set and its weights have different lengths.
+	ErrWeightsLength = errors.New("data and weights must have the same length")
+
+	// ErrNegativeWeight is returned when a weight is negative.
+	ErrNegativeWeight = errors.New("weights must not be negative")
+
+	// ErrInsufficientWeight is returned when fewer than two measurements have positive weights.
+	ErrInsufficientWeight = errors.New("at least two weights must be positive")
+)
+
+// SummarizeGeometric analyzes the given data set of ratios (e.g. speedups) on a log scale and
+// returns a Summary of the natural logarithms of its measurements. The geometric mean of the data
+// set is math.Exp of the summary's Mean. Because the summary is on a log scale, Compare on two such
+// summaries is a test of the ratio of their geometric means, and the resulting Effect and
+// CriticalValue are also on a log scale.
+func SummarizeGeometric(data []float64) (Summary, error) {
+	logs := make([]float64, len(data))
+
+	for i, x := range data {
+		if x <= 0 {
+			return Summary{}, ErrNonPositive
+		}
+
+		logs[i] = math.Log(x)
+	}
+
+	return Summarize(logs), nil
+}
+
+// SummarizeWeighted analyzes the given data set with the given per-measurement weights and returns
+// a Summary of the weighted mean and the unbiased reliability-weighted variance. N is the effective
+// sample size (i.e. Kish's), so the summary can be passed to Compare. If fewer than two
+// measurements have positive weights, ErrInsufficientWeight is returned.
+func SummarizeWeighted(data, weights []float64) (Summary, error) {
+	if len(data) != len(weights) {
+		return Summary{}, ErrWeightsLength
+	}
+
+	var v1, v2 float64
+
+	for _, w := range weights {
+		if w < 0 {
+			return Summary{}, ErrNegativeWeight
+		}
+
+		v1 += w
+		v2 += w * w
+	}
+
+	// With no positive weights, the mean is undefined; with only one, the variance is.
+	if v1 == 0 || v1*v1 == v2 {
+		return Summary{}, ErrInsufficientWeight
+	}
+
+	m := stat.Mean(data, weights)
+	ss := 0.0
 
-	return Summary{Mean: m, Variance: v, N: float64(len(data))}
+	for i, x := range data {
+		ss += weights[i] * (x - m) * (x - m)
+	}
+
+	return Summary{
+		Mean:     m,
+		Variance: ss / (v1 - v2/v1),
+		N:        v1 * v1 / v2,
+		Skewness: math.NaN(),
+		Kurtosis: math.NaN(),
+	}, nil
+}
+
+// SummarizeTrimmed analyzes the given data set after discarding the lowest and highest fraction of
+// its measurements, and returns a Summary of the retained measurements. The fraction must be in the
+// range [0, 0.5).
+func SummarizeTrimmed(data []float64, fraction float64) Summary {
+	if fraction < 0 || fraction >= 0.5 {
+		panic("fraction must be between 0 and 0.5")
+	}
+
+	sorted := sortedCopy(data)
+	k := int(fraction * float64(len(sorted)))
+
+	return Summarize(sorted[k : len(sorted)-k])
+}
+
+// Quartiles are the quartiles of a data set, which are robust to skew and outliers.
+type Quartiles struct {
+	Q1     float64 // Q1 is the first quartile, the 25th percentile of the data set.
+	Median float64 // Median is the second quartile, the 50th percentile of the data set.
+	Q3     float64 // Q3 is the third quartile, the 75th percentile of the data set.
+}
+
+// IQR returns the interquartile range of the data set.
+func (q *Quartiles) IQR() float64 {
+	return q.Q3 - q.Q1
+}
+
+// SummarizeRobust analyzes the given data set and returns both a Summary and its Quartiles. The
+// quartiles are calculated by linear interpolation between the closest ranks of the sorted data,
+// and are NaN if the data set is empty.
+func SummarizeRobust(data []float64) (Summary, Quartiles) {
+	return Summarize(data), quartiles(data)
+}
+
+// Outliers partitions the given data set into measurements which are inside the Tukey fences (i.e.
+// within 1.5 times the interquartile range of the first and third quartiles) and measurements which
+// are outside them. Both returned slices preserve the order of the data set.
+func Outliers(data []float64) (clean, removed []float64) {
+	q := quartiles(data)
+	lo, hi := q.Q1-1.5*q.IQR(), q.Q3+1.5*q.IQR()
+
+	for _, x := range data {
+		if x < lo || x > hi {
+			removed = append(removed, x)
+		} else {
+			clean = append(clean, x)
+		}
+	}
+
+	return clean, removed
+}
+
+// quartiles returns the Quartiles of the given data set.
+func quartiles(data []float64) Quartiles {
+	sorted := sortedCopy(data)
+
+	return Quartiles{
+		Q1:     quantile(sorted, 0.25),
+		Median: quantile(sorted, 0.5),
+		Q3:     quantile(sorted, 0.75),
+	}
+}
+
+// quantile returns the p-quantile of the given sorted data set, linearly interpolating between the
+// closest ranks.
+func quantile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	h := float64(len(sorted)-1) * p
+	i := int(h)
+
+	if i+1 >= len(sorted) {
+		return sorted[i]
+	}
+
+	return sorted[i] + (h-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// sortedCopy returns a sorted copy of the given data set.
+func sortedCopy(data []float64) []float64 {
+	sorted := make([]float64, len(data))
+	copy(sorted, data)
+	sort.Float64s(sorted)
+
+	return sorted
+}
+
+// An Accumulator incrementally summarizes a data set without retaining its measurements, using
+// Welford's online algorithm for the mean and variance. The zero value is ready to use.
+type Accumulator struct {
+	n    float64
+	mean float64
+	m2   float64
+}
+
+// Add adds the given measurement to the accumulated data set.
+func (a *Accumulator) Add(x float64) {
+	a.n++
+
+	delta := x - a.mean
+	a.mean += delta / a.n
+	a.m2 += delta * (x - a.mean)
+}
+
+// Summary returns a Summary of the measurements added so far. As with Summarize, if no measurements
+// have been added, the mean is NaN; if fewer than two have been added, the variance is NaN.
+func (a *Accumulator) Summary() Summary {
+	if a.n == 0 {
+		return Summarize(nil)
+	}
+
+	return Summary{
+		Mean:     a.mean,
+		Variance: a.m2 / (a.n - 1),
+		N:        a.n,
+		Skewness: math.NaN(),
+		Kurtosis: math.NaN(),
+	}
+}
+
+// Merge returns the Summary of the union of the data sets summarized by a and b, as if Summarize
+// had been called on their concatenation. If either data set is empty, the other's Summary is
+// returned.
+func Merge(a, b Summary) Summary {
+	if a.N == 0 {
+		return b
+	}
+
+	if b.N == 0 {
+		return a
+	}
+
+	n := a.N + b.N
+	delta := b.Mean - a.Mean
+
+	// Pool the sums of squared differences from each mean, correcting for the difference in means.
+	m2 := sumSquares(a) + sumSquares(b) + delta*delta*a.N*b.N/n
+
+	return Summary{
+		Mean:     a.Mean + delta*b.N/n,
+		Variance: m2 / (n - 1),
+		N:        n,
+		Skewness: math.NaN(),
+		Kurtosis: math.NaN(),
+	}
+}
+
+// sumSquares returns the sum of squared differences from the mean of the summarized data set.
+func sumSquares(s Summary) float64 {
+	if s.N < 2 {
+		return 0
+	}
+
+	return s.Variance * (s.N - 1)
 }
 
 // Difference represents the statistical difference between two Summary values.
@@ -38,13 +342,26 @@ type Difference struct {
 	// Effect is the absolute difference between the samples' means.
 	Effect float64
 
+	// Delta is the signed difference between the samples' means: the experiment's mean minus the
+	// control's.
+	Delta float64
+
 	// EffectSize is the difference in means between the two samples, normalized for variance.
 	// Technically, this is Cohen's d.
 	EffectSize float64
 
+	// HedgesG is the effect size corrected for the upward bias of Cohen's d in small samples.
+	HedgesG float64
+
 	// CriticalValue is the minimum allowed Effect at the given confidence level.
 	CriticalValue float64
 
+	// TValue is the t-statistic of the test: the Effect divided by its standard error.
+	TValue float64
+
+	// CriticalT is the minimum allowed TValue at the given confidence level.
+	CriticalT float64
+
 	// PValue is the p-value for the test: the probability that accepting the results of this test
 	// will be a Type 1 error, in which the null hypothesis (i.e. there is no difference between the
 	// means of the two samples) will be rejected when it is in fact true.
@@ -56,6 +373,12 @@ type Difference struct {
 	// Beta is the probability of a Type 2 error: the probability that the null hypothesis will be
 	// retained despite it not being true.
 	Beta float64
+
+	// ControlN is the number of measurements in the control sample.
+	ControlN float64
+
+	// ExperimentN is the number of measurements in the experimental sample.
+	ExperimentN float64
 }
 
 // Significant returns true if the difference is statistically significant.
@@ -63,23 +386,151 @@ func (d Difference) Significant() bool {
 	return d.Effect > d.CriticalValue
 }
 
+// Power returns the statistical power of the test: the probability that the null hypothesis will
+// be rejected when it is not true.
+func (d Difference) Power() float64 {
+	return 1 - d.Beta
+}
+
+// ConfidenceInterval returns the confidence interval for Delta at the confidence level of the
+// test: the Delta plus or minus the CriticalValue.
+func (d Difference) ConfidenceInterval() (lo, hi float64) {
+	return d.Delta - d.CriticalValue, d.Delta + d.CriticalValue
+}
+
+// EffectSizeInterval returns the confidence interval for EffectSize at the given confidence level,
+// which must be in the range (0, 100). The interval is calculated using a normal approximation of
+// the sampling distribution of Cohen's d.
+func (d Difference) EffectSizeInterval(confidence float64) (lo, hi float64) {
+	n1, n2 := d.ControlN, d.ExperimentN
+
+	// Calculate the standard error of Cohen's d.
+	se := math.Sqrt((n1+n2)/(n1*n2) + d.EffectSize*d.EffectSize/(2*(n1+n2)))
+
+	// Calculate the two-tailed z-value for the given confidence level.
+	z := distuv.UnitNormal.Quantile(1 - (1-confidence/100)/tails)
+
+	return d.EffectSize - z*se, d.EffectSize + z*se
+}
+
+// CompareData summarizes the two data sets and returns the statistical difference between them using
+// a two-tailed Welch's t-test. The confidence level must be in the range (0, 100). If either data
+// set has fewer than two measurements, ErrInsufficientData is returned.
+func CompareData(control, experiment []float64, confidence float64) (Difference, error) {
+	return Compare(Summarize(control), Summarize(experiment), confidence)
+}
+
 // Compare returns the statistical difference between the two summaries using a two-tailed Welch's
-// t-test. The confidence level must be in the range (0, 100).
-func Compare(control, experiment Summary, confidence float64) Difference {
-	if 0 >= confidence || 1 >= confidence {
-		panic("confidence must be between 0 and 1")
+// t-test. The confidence level must be in the range (0, 100). If either summary has fewer than two
+// measurements, ErrInsufficientData is returned.
+//
+// Welch's t-test does not assume the two samples have equal variances, and is the safe default.
+func Compare(control, experiment Summary, confidence float64) (Difference, error) {
+	if err := validate(control, experiment, confidence); err != nil {
+		return Difference{}, err
+	}
+
+	nu, s := welch(control, experiment)
+
+	return compare(control, experiment, confidence, nu, s), nil
+}
+
+// CompareEquivalence tests whether the two summaries are equivalent within the given margin, which
+// is in the same units as their means, using Schuirmann's two one-sided tests (TOST) procedure with
+// Welch's t-test. The confidence level must be in the range (0, 100). If either summary has fewer
+// than two measurements, ErrInsufficientData is returned.
+//
+// If the returned Difference is significant, the two samples are statistically equivalent within
+// the margin. Its Effect is the distance between the absolute difference in means and the margin,
+// its CriticalValue is the minimum allowed distance at the given confidence level, and its TValue,
+// CriticalT, and PValue are those of the less significant of the two one-sided tests. Its
+// EffectSize and HedgesG are those of the difference in means, and its Beta is not calculated.
+func CompareEquivalence(control, experiment Summary, margin, confidence float64) (Difference, error) {
+	if err := validate(control, experiment, confidence); err != nil {
+		return Difference{}, err
+	}
+
+	nu, s := welch(control, experiment)
+	d := compare(control, experiment, confidence, nu, s)
+
+	// Create a Student's T distribution with the Welch-Satterthwaite degrees of freedom.
+	studentsT := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nu}
+
+	// Calculate the hypothetical one-tailed t-value for the given significance level.
+	d.CriticalT = studentsT.Quantile(1 - d.Alpha)
+	d.CriticalValue = d.CriticalT * s
+
+	// Calculate the distance between the difference and the nearest equivalence bound, which is
+	// the least significant of the two one-sided tests.
+	d.Effect = margin - d.Effect
+	d.TValue = d.Effect / s
+	d.PValue = studentsT.CDF(-d.TValue)
+	d.Beta = math.NaN()
+
+	return d, nil
+}
+
+// ComparePooled returns the statistical difference between the two summaries using a two-tailed
+// Student's t-test with a pooled estimate of the variance. The confidence level must be in the
+// range (0, 100). If either summary has fewer than two measurements, ErrInsufficientData is
+// returned.
+//
+// Student's t-test assumes the two samples have equal variances. If they do, it is slightly more
+// powerful than Welch's t-test; if they do not, its results are unreliable and Compare should be
+// used instead.
+func ComparePooled(control, experiment Summary, confidence float64) (Difference, error) {
+	if err := validate(control, experiment, confidence); err != nil {
+		return Difference{}, err
 	}
 
 	a, b := control, experiment
 
-	// Calculate the significance level.
-	alpha := 1 - (confidence / 100)
+	// Calculate the degrees of freedom.
+	nu := a.N + b.N - 2
+
+	// Calculate the pooled variance.
+	v := (sumSquares(a) + sumSquares(b)) / nu
+
+	// Calculate the standard error.
+	s := math.Sqrt(v/a.N + v/b.N)
 
+	return compare(a, b, confidence, nu, s), nil
+}
+
+// welch returns the Welch-Satterthwaite degrees of freedom and the standard error of the difference
+// between the means of the two summaries.
+func welch(a, b Summary) (nu, s float64) {
 	// Calculate the degrees of freedom.
-	nu := math.Pow(a.Variance/a.N+b.Variance/b.N, 2) /
+	nu = math.Pow(a.Variance/a.N+b.Variance/b.N, 2) /
 		(math.Pow(a.Variance, 2)/(math.Pow(a.N, 2)*(a.N-1)) +
 			math.Pow(b.Variance, 2)/(math.Pow(b.N, 2)*(b.N-1)))
 
+	// Calculate the standard error.
+	s = math.Sqrt(a.Variance/a.N + b.Variance/b.N)
+
+	return nu, s
+}
+
+// validate panics if the confidence level is out of range, and returns ErrInsufficientData if
+// either summary has too few measurements to compare.
+func validate(control, experiment Summary, confidence float64) error {
+	if 0 >= confidence || 1 >= confidence {
+		panic("confidence must be between 0 and 1")
+	}
+
+	if control.N < 2 || experiment.N < 2 {
+		return ErrInsufficientData
+	}
+
+	return nil
+}
+
+// compare returns the statistical difference between the two summaries using a two-tailed t-test
+// with the given degrees of freedom and standard error.
+func compare(a, b Summary, confidence, nu, s float64) Difference {
+	// Calculate the significance level.
+	alpha := 1 - (confidence / 100)
+
 	// Create a Student's T distribution with location of 0, a scale of 1, and a shape of the number
 	// of degrees of freedom in the test.
 	studentsT := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nu}
@@ -90,9 +541,6 @@ func Compare(control, experiment Summary, confidence float64) Difference {
 	// Calculate the absolute difference between the means of the two samples.
 	d := math.Abs(a.Mean - b.Mean)
 
-	// Calculate the standard error.
-	s := math.Sqrt(a.Variance/a.N + b.Variance/b.N)
-
 	// Calculate the experimental t-value.
 	tExp := d / s
 
@@ -108,24 +556,95 @@ func Compare(control, experiment Summary, confidence float64) Difference {
 	// Calculate Cohen's d for the effect size.
 	cd := d / sd
 
+	// Calculate Hedges' g by correcting Cohen's d for small sample bias.
+	g := cd * (1 - 3/(4*(a.N+b.N)-9))
+
 	// Create a standard normal distribution.
 	stdNormal := distuv.UnitNormal
 
 	// Calculate the statistical power.
 	z := d / (sd * math.Sqrt(1/a.N+1/b.N))
 	za := stdNormal.Quantile(1 - alpha/tails)
-	beta := stdNormal.CDF(z-za) - stdNormal.CDF(-z-za)
+	beta := 1 - (stdNormal.CDF(z-za) + stdNormal.CDF(-z-za))
 
 	return Difference{
 		Effect:        d,
+		Delta:         b.Mean - a.Mean,
 		CriticalValue: cv,
+		TValue:        tExp,
+		CriticalT:     tHyp,
 		EffectSize:    cd,
+		HedgesG:       g,
 		PValue:        p,
 		Alpha:         alpha,
 		Beta:          beta,
+		ControlN:      a.N,
+		ExperimentN:   b.N,
 	}
 }
 
+// AdjustPValues returns the given p-values, in their original order, adjusted for multiple
+// comparisons using the given method: either "bonferroni" for the Bonferroni correction, or "holm"
+// for the Holm-Bonferroni method.
+func AdjustPValues(pvalues []float64, method string) []float64 {
+	m := float64(len(pvalues))
+	adjusted := make([]float64, len(pvalues))
+
+	switch method {
+	case "bonferroni":
+		for i, p := range pvalues {
+			adjusted[i] = math.Min(1, p*m)
+		}
+	case "holm":
+		// Order the p-values from smallest to largest.
+		idx := make([]int, len(pvalues))
+		for i := range idx {
+			idx[i] = i
+		}
+
+		sort.SliceStable(idx, func(i, j int) bool {
+			return pvalues[idx[i]] < pvalues[idx[j]]
+		})
+
+		// Scale each p-value by its rank, keeping the adjusted p-values monotonic.
+		prev := 0.0
+		for rank, i := range idx {
+			prev = math.Max(prev, math.Min(1, pvalues[i]*(m-float64(rank))))
+			adjusted[i] = prev
+		}
+	default:
+		panic("method must be either bonferroni or holm")
+	}
+
+	return adjusted
+}
+
+// SampleSize returns the number of measurements required in each sample to detect a difference of
+// the given effect size (i.e. Cohen's d) at the given significance level with the given statistical
+// power, using the same normal approximation as Compare. The significance level and power must both
+// be in the range (0, 1). If the effect size is zero, no number of measurements would be enough,
+// and the largest possible int is returned.
+func SampleSize(effectSize, alpha, power float64) int {
+	if alpha <= 0 || alpha >= 1 || power <= 0 || power >= 1 {
+		panic("alpha and power must be between 0 and 1")
+	}
+
+	stdNormal := distuv.UnitNormal
+	za := stdNormal.Quantile(1 - alpha/tails)
+	zb := stdNormal.Quantile(power)
+
+	// If the effect size is zero, n is infinite (or NaN, if za+zb is also zero).
+	n := math.Ceil(2 * math.Pow((za+zb)/effectSize, 2))
+	if !(n < float64(maxInt)) {
+		return maxInt
+	}
+
+	return int(n)
+}
+
+// maxInt is the largest possible int.
+const maxInt = int(^uint(0) >> 1)
+
 // tails is the number of distribution tails used to determine significance. In this case, we always
 // use a two-tailed test because our null hypothesis is that the samples are not different.
 const tails = 2
@@ -1,6 +1,7 @@
 package tinystat_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/codahale/gubbins/assert"
@@ -18,12 +19,30 @@ func TestSummarizeOdd(t *testing.T) {
 			N:        3,
 			Mean:     2,
 			Variance: 1,
+			Skewness: 0,
+			Kurtosis: math.NaN(),
 		},
-		s, epsilon)
+		s, epsilon, cmpopts.EquateNaNs())
 	assert.Equal(t, "StdDev", 1.0, s.StdDev(), epsilon)
 	assert.Equal(t, "StdErr", 0.5773502691896258, s.StdErr(), epsilon)
 }
 
+func TestSummarizePair(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Summarize([]float64{0.1, 0.7})
+
+	assert.Equal(t, "Summarize",
+		tinystat.Summary{
+			N:        2,
+			Mean:     0.4,
+			Variance: 0.18,
+			Skewness: math.NaN(),
+			Kurtosis: math.NaN(),
+		},
+		s, epsilon, cmpopts.EquateNaNs())
+}
+
 func TestSummarizeEven(t *testing.T) {
 	t.Parallel()
 
@@ -34,27 +53,307 @@ func TestSummarizeEven(t *testing.T) {
 			N:        4,
 			Mean:     2.5,
 			Variance: 1.6666666666666667,
+			Skewness: 0,
+			Kurtosis: -1.2,
 		},
 		s, epsilon)
 	assert.Equal(t, "StdDev", 1.2909944487358056, s.StdDev(), epsilon)
 	assert.Equal(t, "StdErr", 0.6454972243679028, s.StdErr(), epsilon)
 }
 
+func TestSummarizeLargeMagnitude(t *testing.T) {
+	t.Parallel()
+
+	// Naively summing these loses enough precision that the mean is off by 32.
+	data := []float64{1e17 + 128, 1e17 - 224, 1e17 - 448, 1e17 + 352, 1e17 - 592, 1e17 + 144, 1e17 + 240}
+	s := tinystat.Summarize(data)
+
+	assert.Equal(t, "Mean", 1e17-64, s.Mean)
+	assert.Equal(t, "Variance", 132851.80952380953, s.Variance, cmpopts.EquateApprox(1e-9, 0))
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Summarize(nil)
+
+	assert.Equal(t, "N", 0.0, s.N)
+	assert.Equal(t, "Mean", true, math.IsNaN(s.Mean))
+	assert.Equal(t, "Variance", true, math.IsNaN(s.Variance))
+}
+
+func TestSummarizeSingle(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Summarize([]float64{5})
+
+	assert.Equal(t, "N", 1.0, s.N)
+	assert.Equal(t, "Mean", 5.0, s.Mean)
+	assert.Equal(t, "Variance", true, math.IsNaN(s.Variance))
+}
+
+func TestSummarizeSafe(t *testing.T) {
+	t.Parallel()
+
+	s, err := tinystat.SummarizeSafe(iguana)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Summary", tinystat.Summarize(iguana), s)
+
+	_, err = tinystat.SummarizeSafe(nil)
+	assert.Equal(t, "Error", tinystat.ErrNoData, err, cmpopts.EquateErrors())
+
+	s, err = tinystat.SummarizeSafe([]float64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Variance", true, math.IsNaN(s.Variance))
+}
+
+func TestSummarizeSkewed(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Summarize(iguana)
+
+	assert.Equal(t, "Skewness", 1.2065060312235454, s.Skewness, epsilon)
+	assert.Equal(t, "Kurtosis", 1.3178200692041528, s.Kurtosis, epsilon)
+
+	s = tinystat.Summarize(leopard)
+
+	assert.Equal(t, "Skewness", 0.9320093268879031, s.Skewness, epsilon)
+	assert.Equal(t, "Kurtosis", 1.5226184907460825, s.Kurtosis, epsilon)
+}
+
+func TestSummarizeGeometric(t *testing.T) {
+	t.Parallel()
+
+	s, err := tinystat.SummarizeGeometric([]float64{1.5, 2, 3, 1.2, 2.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Geometric Mean", 1.9331820449317627, math.Exp(s.Mean), epsilon)
+	assert.Equal(t, "Variance", 0.13803141363788685, s.Variance, epsilon)
+}
+
+func TestSummarizeGeometricNonPositive(t *testing.T) {
+	t.Parallel()
+
+	_, err := tinystat.SummarizeGeometric([]float64{1.5, 0, 3})
+	assert.Equal(t, "Error", tinystat.ErrNonPositive, err, cmpopts.EquateErrors())
+}
+
+func TestSummarizeWeighted(t *testing.T) {
+	t.Parallel()
+
+	s, err := tinystat.SummarizeWeighted([]float64{1, 2, 3, 4}, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "SummarizeWeighted",
+		tinystat.Summary{
+			N:        3.3333333333333335,
+			Mean:     3,
+			Variance: 1.4285714285714286,
+			Skewness: math.NaN(),
+			Kurtosis: math.NaN(),
+		},
+		s, epsilon, cmpopts.EquateNaNs())
+}
+
+func TestSummarizeWeightedUniform(t *testing.T) {
+	t.Parallel()
+
+	s, err := tinystat.SummarizeWeighted(leopard, []float64{2, 2, 2, 2, 2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "SummarizeWeighted", withoutShape(tinystat.Summarize(leopard)), s, epsilon, cmpopts.EquateNaNs())
+}
+
+func TestSummarizeWeightedErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := tinystat.SummarizeWeighted([]float64{1, 2}, []float64{1})
+	assert.Equal(t, "Error", tinystat.ErrWeightsLength, err, cmpopts.EquateErrors())
+
+	_, err = tinystat.SummarizeWeighted([]float64{1, 2}, []float64{1, -1})
+	assert.Equal(t, "Error", tinystat.ErrNegativeWeight, err, cmpopts.EquateErrors())
+
+	_, err = tinystat.SummarizeWeighted([]float64{1, 2}, []float64{0, 0})
+	assert.Equal(t, "Error", tinystat.ErrInsufficientWeight, err, cmpopts.EquateErrors())
+
+	_, err = tinystat.SummarizeWeighted([]float64{1, 2, 3}, []float64{0, 3, 0})
+	assert.Equal(t, "Error", tinystat.ErrInsufficientWeight, err, cmpopts.EquateErrors())
+}
+
+func TestSummarizeTrimmed(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.SummarizeTrimmed(append([]float64{1e6}, iguana...), 0.2)
+
+	assert.Equal(t, "N", 6.0, s.N)
+	assert.Equal(t, "Mean", 341.6666666666667, s.Mean, epsilon)
+	assert.Equal(t, "Untrimmed", tinystat.Summarize(iguana), tinystat.SummarizeTrimmed(iguana, 0),
+		epsilon)
+}
+
+func TestSummarizeRobustOdd(t *testing.T) {
+	t.Parallel()
+
+	s, q := tinystat.SummarizeRobust(iguana)
+
+	assert.Equal(t, "Summary", tinystat.Summarize(iguana), s, epsilon)
+	assert.Equal(t, "Quartiles",
+		tinystat.Quartiles{
+			Q1:     150,
+			Median: 200,
+			Q3:     400,
+		},
+		q, epsilon)
+	assert.Equal(t, "IQR", 250.0, q.IQR(), epsilon)
+}
+
+func TestSummarizeRobustEven(t *testing.T) {
+	t.Parallel()
+
+	_, q := tinystat.SummarizeRobust(leopard)
+
+	assert.Equal(t, "Quartiles",
+		tinystat.Quartiles{
+			Q1:     514.75,
+			Median: 619,
+			Q3:     704.5,
+		},
+		q, epsilon)
+	assert.Equal(t, "IQR", 189.75, q.IQR(), epsilon)
+}
+
+func TestSummarizeRobustSmall(t *testing.T) {
+	t.Parallel()
+
+	_, q := tinystat.SummarizeRobust([]float64{5})
+
+	assert.Equal(t, "Quartiles",
+		tinystat.Quartiles{
+			Q1:     5,
+			Median: 5,
+			Q3:     5,
+		},
+		q, epsilon)
+
+	_, q = tinystat.SummarizeRobust([]float64{3, 1})
+
+	assert.Equal(t, "Quartiles",
+		tinystat.Quartiles{
+			Q1:     1.5,
+			Median: 2,
+			Q3:     2.5,
+		},
+		q, epsilon)
+}
+
+func TestOutliers(t *testing.T) {
+	t.Parallel()
+
+	clean, removed := tinystat.Outliers(append([]float64{5000}, iguana...))
+
+	assert.Equal(t, "Clean", iguana, clean)
+	assert.Equal(t, "Removed", []float64{5000}, removed)
+}
+
+func TestOutliersNone(t *testing.T) {
+	t.Parallel()
+
+	clean, removed := tinystat.Outliers(chameleon)
+
+	assert.Equal(t, "Clean", chameleon, clean)
+	assert.Equal(t, "Removed", []float64(nil), removed)
+}
+
+func TestAccumulator(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range [][]float64{chameleon, iguana, leopard} {
+		var acc tinystat.Accumulator
+		for _, x := range data {
+			acc.Add(x)
+		}
+
+		assert.Equal(t, "Summary", withoutShape(tinystat.Summarize(data)), acc.Summary(), epsilon,
+			cmpopts.EquateNaNs())
+	}
+}
+
+func TestAccumulatorEmpty(t *testing.T) {
+	t.Parallel()
+
+	var acc tinystat.Accumulator
+
+	assert.Equal(t, "Summary", tinystat.Summarize(nil), acc.Summary(), cmpopts.EquateNaNs())
+
+	acc.Add(leopard[0])
+
+	assert.Equal(t, "Summary", withoutShape(tinystat.Summarize(leopard[:1])), acc.Summary(),
+		cmpopts.EquateNaNs())
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	a := tinystat.Summarize(leopard[:3])
+	b := tinystat.Summarize(leopard[3:])
+
+	assert.Equal(t, "Merge", withoutShape(tinystat.Summarize(leopard)), tinystat.Merge(a, b), epsilon,
+		cmpopts.EquateNaNs())
+}
+
+func TestMergeEmpty(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Summarize(leopard)
+
+	assert.Equal(t, "Merge", s, tinystat.Merge(tinystat.Summarize(nil), s))
+	assert.Equal(t, "Merge", s, tinystat.Merge(s, tinystat.Summarize(nil)))
+}
+
+func TestMergeSingles(t *testing.T) {
+	t.Parallel()
+
+	s := tinystat.Merge(tinystat.Summarize(leopard[:1]), tinystat.Summarize(leopard[1:2]))
+
+	assert.Equal(t, "Merge", withoutShape(tinystat.Summarize(leopard[:2])), s, epsilon, cmpopts.EquateNaNs())
+}
+
 func TestCompareSimilarData(t *testing.T) {
 	t.Parallel()
 
 	a := tinystat.Summarize([]float64{1, 2, 3, 4})
 	b := tinystat.Summarize([]float64{1, 2, 3, 4})
-	d := tinystat.Compare(a, b, 80)
+	d, err := tinystat.Compare(a, b, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	assert.Equal(t, "Compare",
 		tinystat.Difference{
 			Effect:        0,
+			Delta:         0,
 			EffectSize:    0,
+			HedgesG:       0,
 			CriticalValue: 1.31431116679138120,
+			TValue:        0,
+			CriticalT:     1.4397557472651483,
 			PValue:        1,
 			Alpha:         0.19999999999999996,
-			Beta:          0,
+			Beta:          0.8,
+			ControlN:      4,
+			ExperimentN:   4,
 		},
 		d, epsilon)
 	assert.Equal(t, "Significant", false, d.Significant())
@@ -65,19 +364,233 @@ func TestCompareDifferentData(t *testing.T) {
 
 	a := tinystat.Summarize([]float64{1, 2, 3, 4})
 	b := tinystat.Summarize([]float64{10, 20, 30, 40})
-	d := tinystat.Compare(a, b, 80)
+	d, err := tinystat.Compare(a, b, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	assert.Equal(t, "Compare",
 		tinystat.Difference{
 			Effect:        22.5,
+			Delta:         22.5,
 			EffectSize:    2.452519415855564,
+			HedgesG:       2.1326255790048383,
 			CriticalValue: 10.568344341563606,
+			TValue:        3.468386219886279,
+			CriticalT:     1.6291155502796595,
 			PValue:        0.03916791618893338,
 			Alpha:         0.19999999999999996,
-			Beta:          0.9856216842773273,
+			Beta:          0.014376280930277874,
+			ControlN:      4,
+			ExperimentN:   4,
 		},
 		d, epsilon)
 	assert.Equal(t, "Significant", true, d.Significant())
+	assert.Equal(t, "Power", 1-0.014376280930277874, d.Power(), epsilon)
+
+	lo, hi := d.ConfidenceInterval()
+	assert.Equal(t, "ConfidenceInterval", []float64{11.931655658436394, 33.068344341563606},
+		[]float64{lo, hi}, epsilon)
+
+	lo, hi = d.EffectSizeInterval(95)
+	assert.Equal(t, "EffectSizeInterval", []float64{0.6181688008840507, 4.286870030827077},
+		[]float64{lo, hi}, epsilon)
+}
+
+func TestCompareConfidenceIntervalSign(t *testing.T) {
+	t.Parallel()
+
+	a := tinystat.Summarize([]float64{1, 2, 3, 4})
+	b := tinystat.Summarize([]float64{10, 20, 30, 40})
+	d, err := tinystat.Compare(b, a, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Delta", -22.5, d.Delta, epsilon)
+
+	lo, hi := d.ConfidenceInterval()
+	assert.Equal(t, "ConfidenceInterval", []float64{-33.068344341563606, -11.931655658436394},
+		[]float64{lo, hi}, epsilon)
+}
+
+func TestCompareTValue(t *testing.T) {
+	t.Parallel()
+
+	for _, experiment := range [][]float64{chameleon, leopard} {
+		d, err := tinystat.CompareData(iguana, experiment, 95)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "Significant", d.Significant(), d.TValue > d.CriticalT)
+	}
+}
+
+func TestComparePooled(t *testing.T) {
+	t.Parallel()
+
+	d, err := tinystat.ComparePooled(tinystat.Summarize(iguana), tinystat.Summarize(leopard), 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "TValue", 2.5835583387029963, d.TValue, epsilon)
+	assert.Equal(t, "CriticalT", 2.200985160091639, d.CriticalT, epsilon)
+	assert.Equal(t, "CriticalValue", 292.6345386382976, d.CriticalValue, epsilon)
+	assert.Equal(t, "PValue", 0.025428528177754383, d.PValue, epsilon)
+	assert.Equal(t, "Significant", true, d.Significant())
 }
 
-var epsilon = cmpopts.EquateApprox(0.001, 0.001) //nolint:gochecknoglobals // testing
+func TestCompareEquivalence(t *testing.T) {
+	t.Parallel()
+
+	a := tinystat.Summarize([]float64{1, 2, 3, 4})
+	b := tinystat.Summarize([]float64{1.1, 2.1, 3.1, 4.1})
+
+	d, err := tinystat.CompareEquivalence(a, b, 3, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Effect", 2.9, d.Effect, epsilon)
+	assert.Equal(t, "CriticalValue", 1.7738727882290781, d.CriticalValue, epsilon)
+	assert.Equal(t, "TValue", 3.1767908335299633, d.TValue, epsilon)
+	assert.Equal(t, "CriticalT", 1.9431802805153011, d.CriticalT, epsilon)
+	assert.Equal(t, "PValue", 0.009576728703675888, d.PValue, epsilon)
+	assert.Equal(t, "Significant", true, d.Significant())
+}
+
+func TestCompareEquivalenceDivergent(t *testing.T) {
+	t.Parallel()
+
+	d, err := tinystat.CompareEquivalence(tinystat.Summarize(iguana), tinystat.Summarize(leopard),
+		100, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Significant", false, d.Significant())
+}
+
+func TestCompareData(t *testing.T) {
+	t.Parallel()
+
+	want, err := tinystat.Compare(tinystat.Summarize(iguana), tinystat.Summarize(leopard), 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tinystat.CompareData(iguana, leopard, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "CompareData", want, got, epsilon)
+
+	_, err = tinystat.CompareData(iguana, nil, 95)
+	assert.Equal(t, "Error", tinystat.ErrInsufficientData, err, cmpopts.EquateErrors())
+}
+
+func TestCompareInsufficientData(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range [][]float64{nil, {5}} {
+		_, err := tinystat.Compare(tinystat.Summarize(leopard), tinystat.Summarize(data), 95)
+		assert.Equal(t, "Error", tinystat.ErrInsufficientData, err, cmpopts.EquateErrors())
+
+		_, err = tinystat.Compare(tinystat.Summarize(data), tinystat.Summarize(leopard), 95)
+		assert.Equal(t, "Error", tinystat.ErrInsufficientData, err, cmpopts.EquateErrors())
+	}
+}
+
+func TestCompareHedgesG(t *testing.T) {
+	t.Parallel()
+
+	d, err := tinystat.Compare(tinystat.Summarize(iguana), tinystat.Summarize(chameleon), 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "EffectSize", 0.887925158462644, d.EffectSize, epsilon)
+	assert.Equal(t, "HedgesG", 0.8196232231962868, d.HedgesG, epsilon)
+}
+
+func TestSampleSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "SampleSize", 63, tinystat.SampleSize(0.5, 0.05, 0.8))
+	assert.Equal(t, "SampleSize", 85, tinystat.SampleSize(0.5, 0.05, 0.9))
+}
+
+func TestSampleSizePower(t *testing.T) {
+	t.Parallel()
+
+	// Two samples of the recommended size, with the given effect size, should have the given power.
+	n := float64(tinystat.SampleSize(0.5, 0.05, 0.8))
+	a := tinystat.Summary{N: n, Mean: 0, Variance: 1}
+	b := tinystat.Summary{N: n, Mean: 0.5, Variance: 1}
+
+	d, err := tinystat.Compare(a, b, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Power", 0.8, d.Power(), cmpopts.EquateApprox(0, 0.01))
+}
+
+func TestSampleSizeZeroEffect(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "SampleSize", int(^uint(0)>>1), tinystat.SampleSize(0, 0.05, 0.8))
+}
+
+func TestSampleSizeInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][2]float64{{0, 0.8}, {1, 0.8}, {0.05, 0}, {0.05, 1}} {
+		func() {
+			defer func() {
+				assert.Equal(t, "Panic", "alpha and power must be between 0 and 1", recover())
+			}()
+
+			tinystat.SampleSize(0.5, args[0], args[1])
+		}()
+	}
+}
+
+func TestAdjustPValuesBonferroni(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "AdjustPValues",
+		[]float64{0.04, 0.16, 0.12, 0.02, 1},
+		tinystat.AdjustPValues([]float64{0.008, 0.032, 0.024, 0.004, 0.5}, "bonferroni"),
+		epsilon)
+}
+
+func TestAdjustPValuesHolm(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "AdjustPValues",
+		[]float64{0.03, 0.06, 0.06, 0.02},
+		tinystat.AdjustPValues([]float64{0.01, 0.04, 0.03, 0.005}, "holm"),
+		epsilon)
+}
+
+// withoutShape returns the given Summary with the skewness and kurtosis, which only Summarize
+// calculates, set to NaN.
+func withoutShape(s tinystat.Summary) tinystat.Summary {
+	s.Skewness, s.Kurtosis = math.NaN(), math.NaN()
+
+	return s
+}
+
+//nolint:gochecknoglobals // testing
+var epsilon = cmpopts.EquateApprox(0.001, 0.001)
+
+//nolint:gochecknoglobals // testing
+var (
+	chameleon = []float64{150, 400, 720, 500, 930}
+	iguana    = []float64{50, 200, 150, 400, 750, 400, 150}
+	leopard   = []float64{353, 574, 495, 1057, 664, 718}
+)